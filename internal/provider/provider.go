@@ -491,10 +491,13 @@ func New(ctx context.Context) (*schema.Provider, error) {
 
 			"aws_codestarconnections_connection": codestarconnections.DataSourceConnection(),
 
+			"aws_cognito_user":                          cognitoidp.DataSourceUser(),
 			"aws_cognito_user_pool_client":              cognitoidp.DataSourceUserPoolClient(),
 			"aws_cognito_user_pool_clients":             cognitoidp.DataSourceUserPoolClients(),
 			"aws_cognito_user_pool_signing_certificate": cognitoidp.DataSourceUserPoolSigningCertificate(),
+			"aws_cognito_user_pool_user_count":          cognitoidp.DataSourceUserPoolUserCount(),
 			"aws_cognito_user_pools":                    cognitoidp.DataSourceUserPools(),
+			"aws_cognito_users":                         cognitoidp.DataSourceUsers(),
 
 			"aws_connect_bot_association":             connect.DataSourceBotAssociation(),
 			"aws_connect_contact_flow":                connect.DataSourceContactFlow(),
@@ -782,17 +785,18 @@ func New(ctx context.Context) (*schema.Provider, error) {
 			"aws_networkfirewall_firewall":        networkfirewall.DataSourceFirewall(),
 			"aws_networkfirewall_firewall_policy": networkfirewall.DataSourceFirewallPolicy(),
 
-			"aws_networkmanager_connection":                   networkmanager.DataSourceConnection(),
-			"aws_networkmanager_connections":                  networkmanager.DataSourceConnections(),
-			"aws_networkmanager_core_network_policy_document": networkmanager.DataSourceCoreNetworkPolicyDocument(),
-			"aws_networkmanager_device":                       networkmanager.DataSourceDevice(),
-			"aws_networkmanager_devices":                      networkmanager.DataSourceDevices(),
-			"aws_networkmanager_global_network":               networkmanager.DataSourceGlobalNetwork(),
-			"aws_networkmanager_global_networks":              networkmanager.DataSourceGlobalNetworks(),
-			"aws_networkmanager_link":                         networkmanager.DataSourceLink(),
-			"aws_networkmanager_links":                        networkmanager.DataSourceLinks(),
-			"aws_networkmanager_site":                         networkmanager.DataSourceSite(),
-			"aws_networkmanager_sites":                        networkmanager.DataSourceSites(),
+			"aws_networkmanager_connection":                     networkmanager.DataSourceConnection(),
+			"aws_networkmanager_connections":                    networkmanager.DataSourceConnections(),
+			"aws_networkmanager_core_network_policy_attachment": networkmanager.DataSourceCoreNetworkPolicyAttachment(),
+			"aws_networkmanager_core_network_policy_document":   networkmanager.DataSourceCoreNetworkPolicyDocument(),
+			"aws_networkmanager_device":                         networkmanager.DataSourceDevice(),
+			"aws_networkmanager_devices":                        networkmanager.DataSourceDevices(),
+			"aws_networkmanager_global_network":                 networkmanager.DataSourceGlobalNetwork(),
+			"aws_networkmanager_global_networks":                networkmanager.DataSourceGlobalNetworks(),
+			"aws_networkmanager_link":                           networkmanager.DataSourceLink(),
+			"aws_networkmanager_links":                          networkmanager.DataSourceLinks(),
+			"aws_networkmanager_site":                           networkmanager.DataSourceSite(),
+			"aws_networkmanager_sites":                          networkmanager.DataSourceSites(),
 
 			"aws_opensearch_domain": opensearch.DataSourceDomain(),
 
@@ -2,6 +2,8 @@ package cognitoidp
 
 import (
 	"time"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 )
 
 const (
@@ -18,3 +20,24 @@ const (
 const (
 	propagationTimeout = 2 * time.Minute
 )
+
+const (
+	userAttributeMergeStrategyConfigAuthoritative = "config_authoritative"
+	userAttributeMergeStrategyServerAuthoritative = "server_authoritative"
+)
+
+func userAttributeMergeStrategy_Values() []string {
+	return []string{
+		userAttributeMergeStrategyConfigAuthoritative,
+		userAttributeMergeStrategyServerAuthoritative,
+	}
+}
+
+// userDesiredStatus_Values returns the user statuses that desired_status can reconcile toward.
+func userDesiredStatus_Values() []string {
+	return []string{
+		cognitoidentityprovider.UserStatusTypeConfirmed,
+		cognitoidentityprovider.UserStatusTypeForceChangePassword,
+		cognitoidentityprovider.UserStatusTypeResetRequired,
+	}
+}
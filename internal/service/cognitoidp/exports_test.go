@@ -0,0 +1,77 @@
+package cognitoidp
+
+import (
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+// Exports for use in tests only.
+var (
+	ApplyUserAttributesUpdate                = applyUserAttributesUpdate
+	ApplyUserVerifiedAttributes              = applyUserVerifiedAttributes
+	ExpandUserMFAOptions                     = expandUserMFAOptions
+	ExpandUserPassword                       = expandUserPassword
+	ExpandAttribute                          = expandAttribute
+	FlattenUserAttributes                    = flattenUserAttributes
+	ExpandUserTagAttributes                  = expandUserTagAttributes
+	FlattenUserIdentities                    = flattenUserIdentities
+	FlattenUserMFAOptions                    = flattenUserMFAOptions
+	FlattenUserTagAttributes                 = flattenUserTagAttributes
+	IsImmutableStandardAttribute             = isImmutableStandardAttribute
+	FlattenUserAttributeBlocks               = flattenUserAttributeBlocks
+	FlattenUserAttributeList                 = flattenUserAttributeList
+	MergeUserAttributes                      = mergeUserAttributes
+	UserAttributeKeysNotAllowed              = userAttributeKeysNotAllowed
+	ReconcileUserAttributes                  = reconcileUserAttributes
+	RetryUserOperation                       = retryUserOperation
+	PartitionUserAttributes                  = partitionUserAttributes
+	NormalizeUserPhoneNumber                 = normalizeUserPhoneNumber
+	UserAttributeAPIName                     = userAttributeAPIName
+	MergeUserAttributesJSON                  = mergeUserAttributesJSON
+	UserAttributeKey                         = userAttributeKey
+	UserAttributeKeyCollisions               = userAttributeKeyCollisions
+	UserImmutableAttributeKeys               = userImmutableAttributeKeys
+	UserAttributeKeysWithTagPrefix           = userAttributeKeysWithTagPrefix
+	UserAttributesFromConfig                 = userAttributesFromConfig
+	UserAttributeUpdateBatches               = userAttributeUpdateBatches
+	UserAttributeValuesTooLong               = userAttributeValuesTooLong
+	UserAttributesNotInSchema                = userAttributesNotInSchema
+	UserAttributesPropagated                 = userAttributesPropagated
+	UserAttributeServerAuthoritativeSuppress = userAttributeServerAuthoritativeSuppress
+	UserPasswordResetRequired                = userPasswordResetRequired
+	UserMFAEnrolledAt                        = userMFAEnrolledAt
+	UserMFAFallbackOrder                     = userMFAFallbackOrder
+	UserMFASettingsError                     = userMFASettingsError
+	UserParseImportID                        = userParseImportID
+	UserPasswordPolicyError                  = userPasswordPolicyError
+	UserPoolUsernameCaseSensitive            = userPoolUsernameCaseSensitive
+	UserStatusCounts                         = userStatusCounts
+	UserSMSMFAPhoneNumberError               = userSMSMFAPhoneNumberError
+	UserStatusTransitionError                = userStatusTransitionError
+	UserConfirmSignUpError                   = userConfirmSignUpError
+	UserCreateResourceID                     = userCreateResourceID
+	UserCreateAdoptsExisting                 = userCreateAdoptsExisting
+	InvalidateUserCache                      = invalidateUserCache
+	UserCache                                = &userCache
+	UserCacheKey                             = userCacheKey
+	UserMessageSuppressedWithoutPassword     = userMessageSuppressedWithoutPassword
+	UserAliasExistsError                     = userAliasExistsError
+	OmitUserSensitiveAttributes              = omitUserSensitiveAttributes
+	OmitUserAttributes                       = omitUserAttributes
+	ExpandUserSensitiveAttributes            = expandUserSensitiveAttributes
+	UserSensitiveAttributesHash              = userSensitiveAttributesHash
+	UserSensitiveAttributesRemoved           = userSensitiveAttributesRemoved
+	UserAutoVerifiedAttributes               = userAutoVerifiedAttributes
+	UserCreateDivergence                     = userCreateDivergence
+	UserDefaultDeliveryMediums               = userDefaultDeliveryMediums
+	UserIdentityHash                         = userIdentityHash
+)
+
+// StubUserConn replaces the connection used by the user resource and returns a function that restores it.
+func StubUserConn(f func(interface{}) *cognitoidentityprovider.CognitoIdentityProvider) func() {
+	old := userConn
+	userConn = f
+
+	return func() {
+		userConn = old
+	}
+}
@@ -78,6 +78,45 @@ func FindCognitoUserInGroup(ctx context.Context, conn *cognitoidentityprovider.C
 	return found, nil
 }
 
+// FindUserGroupNames returns the names of the groups the specified user is a member of.
+func FindUserGroupNames(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string) ([]string, error) {
+	input := &cognitoidentityprovider.AdminListGroupsForUserInput{
+		UserPoolId: aws.String(userPoolID),
+		Username:   aws.String(username),
+	}
+
+	var output []string
+
+	err := conn.AdminListGroupsForUserPagesWithContext(ctx, input, func(page *cognitoidentityprovider.AdminListGroupsForUserOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, group := range page.Groups {
+			if group == nil {
+				continue
+			}
+
+			output = append(output, aws.StringValue(group.GroupName))
+		}
+
+		return !lastPage
+	})
+
+	if tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeUserNotFoundException, cognitoidentityprovider.ErrCodeResourceNotFoundException) {
+		return nil, &resource.NotFoundError{
+			LastError:   err,
+			LastRequest: input,
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
 func FindCognitoUserPoolClient(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolId, clientId string) (*cognitoidentityprovider.UserPoolClientType, error) {
 	input := &cognitoidentityprovider.DescribeUserPoolClientInput{
 		ClientId:   aws.String(clientId),
@@ -104,6 +143,117 @@ func FindCognitoUserPoolClient(ctx context.Context, conn *cognitoidentityprovide
 	return output.UserPoolClient, nil
 }
 
+func FindUserPoolByID(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, id string) (*cognitoidentityprovider.UserPoolType, error) {
+	input := &cognitoidentityprovider.DescribeUserPoolInput{
+		UserPoolId: aws.String(id),
+	}
+
+	output, err := conn.DescribeUserPoolWithContext(ctx, input)
+
+	if tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeResourceNotFoundException) {
+		return nil, &resource.NotFoundError{
+			LastError:   err,
+			LastRequest: input,
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if output == nil || output.UserPool == nil {
+		return nil, tfresource.NewEmptyResultError(input)
+	}
+
+	return output.UserPool, nil
+}
+
+// FindGroupByTwoPartKey returns the group with the given name in the user pool.
+func FindGroupByTwoPartKey(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, groupName string) (*cognitoidentityprovider.GroupType, error) {
+	input := &cognitoidentityprovider.GetGroupInput{
+		GroupName:  aws.String(groupName),
+		UserPoolId: aws.String(userPoolID),
+	}
+
+	output, err := conn.GetGroupWithContext(ctx, input)
+
+	if tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeResourceNotFoundException) {
+		return nil, &resource.NotFoundError{
+			LastError:   err,
+			LastRequest: input,
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if output == nil || output.Group == nil {
+		return nil, tfresource.NewEmptyResultError(input)
+	}
+
+	return output.Group, nil
+}
+
+// FindUserStatusCounts returns the number of users in the user pool by user status.
+// All users in the pool are enumerated.
+func FindUserStatusCounts(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID string) (map[string]int, error) {
+	input := &cognitoidentityprovider.ListUsersInput{
+		UserPoolId: aws.String(userPoolID),
+	}
+
+	users, err := FindUsers(ctx, conn, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return userStatusCounts(users), nil
+}
+
+func FindUsers(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, input *cognitoidentityprovider.ListUsersInput) ([]*cognitoidentityprovider.UserType, error) {
+	return FindUsersWithMaxResults(ctx, conn, input, 0)
+}
+
+// FindUsersWithMaxResults returns at most maxResults users, reading only as many pages as needed.
+// If maxResults is 0, all users are returned.
+func FindUsersWithMaxResults(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, input *cognitoidentityprovider.ListUsersInput, maxResults int) ([]*cognitoidentityprovider.UserType, error) {
+	var users []*cognitoidentityprovider.UserType
+
+	err := conn.ListUsersPagesWithContext(ctx, input, func(page *cognitoidentityprovider.ListUsersOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, v := range page.Users {
+			if v != nil {
+				users = append(users, v)
+			}
+		}
+
+		if maxResults > 0 && len(users) >= maxResults {
+			users = users[:maxResults]
+
+			return false
+		}
+
+		return !lastPage
+	})
+
+	if tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeResourceNotFoundException) {
+		return nil, &resource.NotFoundError{
+			LastError:   err,
+			LastRequest: input,
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func FindRiskConfigurationById(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, id string) (*cognitoidentityprovider.RiskConfigurationType, error) {
 	userPoolId, clientId, err := RiskConfigurationParseID(id)
 	if err != nil {
@@ -137,3 +287,27 @@ func FindRiskConfigurationById(ctx context.Context, conn *cognitoidentityprovide
 
 	return output.RiskConfiguration, nil
 }
+
+// FindUserBySub returns the user with the specified sub, found by listing the users in the user pool.
+func FindUserBySub(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, sub string) (*cognitoidentityprovider.UserType, error) {
+	input := &cognitoidentityprovider.ListUsersInput{
+		Filter:     aws.String(fmt.Sprintf("sub = %q", sub)),
+		UserPoolId: aws.String(userPoolID),
+	}
+
+	users, err := FindUsers(ctx, conn, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, tfresource.NewEmptyResultError(input)
+	}
+
+	if count := len(users); count > 1 {
+		return nil, tfresource.NewTooManyResultsError(count, input)
+	}
+
+	return users[0], nil
+}
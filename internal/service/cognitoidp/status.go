@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-aws/internal/tfresource"
 )
 
 const (
@@ -33,3 +34,19 @@ func statusUserPoolDomain(ctx context.Context, conn *cognitoidentityprovider.Cog
 		return output, aws.StringValue(output.DomainDescription.Status), nil
 	}
 }
+
+func statusUser(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		output, err := FindUserByTwoPartKey(ctx, conn, userPoolID, username)
+
+		if tfresource.NotFound(err) {
+			return nil, "", nil
+		}
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		return output, aws.StringValue(output.UserStatus), nil
+	}
+}
@@ -2,15 +2,26 @@ package cognitoidp
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 	"github.com/hashicorp/aws-sdk-go-base/v2/awsv1shim/v2/tfawserr"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -18,7 +29,9 @@ import (
 	"github.com/hashicorp/terraform-provider-aws/internal/conns"
 	"github.com/hashicorp/terraform-provider-aws/internal/create"
 	"github.com/hashicorp/terraform-provider-aws/internal/errs/sdkdiag"
+	"github.com/hashicorp/terraform-provider-aws/internal/flex"
 	"github.com/hashicorp/terraform-provider-aws/internal/tfresource"
+	"github.com/hashicorp/terraform-provider-aws/internal/verify"
 	"github.com/hashicorp/terraform-provider-aws/names"
 )
 
@@ -33,118 +46,539 @@ func ResourceUser() *schema.Resource {
 			StateContext: resourceUserImport,
 		},
 
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceUserV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: UserStateUpgradeV0,
+				Version: 0,
+			},
+		},
+
+		CustomizeDiff: resourceUserCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
+
 		// https://docs.aws.amazon.com/cognito-user-identity-pools/latest/APIReference/API_AdminCreateUser.html
-		Schema: map[string]*schema.Schema{
-			"attributes": {
-				Type: schema.TypeMap,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
-				},
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					if k == "attributes.sub" || k == "attributes.%" {
-						return true
-					}
+		Schema: resourceUserSchema(),
+	}
+}
 
-					return false
+// resourceUserSchema returns the schema of the user resource.
+// Version 0 states, with the schema of resourceUserV0, are upgraded by UserStateUpgradeV0.
+func resourceUserSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"attribute": {
+			Type:          schema.TypeSet,
+			Optional:      true,
+			ConflictsWith: []string{"attributes"},
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"value": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
 				},
-				Optional: true,
-			},
-			"client_metadata": {
-				Type:     schema.TypeMap,
-				Elem:     &schema.Schema{Type: schema.TypeString},
-				Optional: true,
 			},
-			"creation_date": {
-				Type:     schema.TypeString,
-				Computed: true,
+		},
+		"attributes": {
+			Type: schema.TypeMap,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
 			},
-			"desired_delivery_mediums": {
-				Type: schema.TypeSet,
-				Elem: &schema.Schema{
-					Type:         schema.TypeString,
-					ValidateFunc: validation.StringInSlice(cognitoidentityprovider.DeliveryMediumType_Values(), false),
-				},
-				Optional: true,
+			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+				if k == "attributes.sub" || k == "attributes.%" {
+					return true
+				}
+
+				// Changes to sensitive attributes are planned via sensitive_attributes_hash so that their values are redacted.
+				if _, ok := userSensitiveAttributeNames(flex.ExpandStringValueSet(d.Get("sensitive_attributes").(*schema.Set)))[userAttributeAPIName(strings.TrimPrefix(k, "attributes."))]; ok {
+					return true
+				}
+
+				// The email_verified and phone_number_verified booleans take precedence over attributes.
+				if _, ok := userConfiguredVerifiedAttributes(d)[strings.TrimPrefix(k, "attributes.")]; ok {
+					return true
+				}
+
+				// Verified attributes set by auto_verify aren't configured.
+				if new == "" && d.Get("auto_verify").(*schema.Set).Contains(strings.TrimSuffix(strings.TrimPrefix(k, "attributes."), "_verified")) {
+					return true
+				}
+
+				// phone_number is stored by Cognito in its normalized E.164 form.
+				if k == "attributes.phone_number" {
+					if v, err := normalizeUserPhoneNumber(new, d.Get("default_phone_country").(string)); err == nil && v == old {
+						return true
+					}
+				}
+
+				if d.Get("attribute_merge_strategy").(string) == userAttributeMergeStrategyServerAuthoritative {
+					return userAttributeServerAuthoritativeSuppress(strings.TrimPrefix(k, "attributes."), new, d.Get("seeded_attributes").(map[string]interface{}))
+				}
+
+				return false
 			},
-			"enabled": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  true,
+			Optional: true,
+		},
+		"attribute_apply_order": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"attribute_merge_strategy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      userAttributeMergeStrategyConfigAuthoritative,
+			ValidateFunc: validation.StringInSlice(userAttributeMergeStrategy_Values(), false),
+		},
+		"allowed_attribute_keys": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"attributes_document": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validUserAttributesDocument,
+		},
+		"attributes_json": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validUserAttributesDocument,
+		},
+		"auto_delivery_medium": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"auto_verify": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(userAutoVerifyAttributes, false),
 			},
-			"force_alias_creation": {
-				Type:     schema.TypeBool,
-				Optional: true,
+		},
+		"client_metadata": {
+			Type:     schema.TypeMap,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+			Optional: true,
+		},
+		"confirm": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"consistent_read": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"creation_date": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"default_groups": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validUserGroupName,
 			},
-			"last_modified_date": {
-				Type:     schema.TypeString,
-				Computed: true,
+		},
+		"default_phone_country": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringMatch(regexp.MustCompile(`^\+?[1-9][0-9]{0,2}$`), "must be a country calling code, e.g. 1 or +44"),
+		},
+		"custom_attributes": {
+			Type:     schema.TypeMap,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"desired_delivery_mediums": {
+			Type: schema.TypeSet,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(cognitoidentityprovider.DeliveryMediumType_Values(), false),
 			},
-			"message_action": {
+			Optional: true,
+		},
+		"desired_status": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice(userDesiredStatus_Values(), false),
+		},
+		"email_verified": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Computed: true,
+		},
+		"enabled": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		"force_alias_creation": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"force_password_reset": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"global_sign_out": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"groups": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Computed: true,
+			Elem: &schema.Schema{
 				Type:         schema.TypeString,
-				Optional:     true,
-				ValidateFunc: validation.StringInSlice(cognitoidentityprovider.MessageActionType_Values(), false),
+				ValidateFunc: validUserGroupName,
 			},
-			"mfa_setting_list": {
-				Type: schema.TypeSet,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+		},
+		"identities": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"primary": {
+						Type:     schema.TypeBool,
+						Computed: true,
+					},
+					"provider_name": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"provider_type": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"user_id": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
 				},
-				Computed: true,
-			},
-			"preferred_mfa_setting": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"user_pool_id": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-			},
-			"username": {
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     true,
-				ValidateFunc: validation.StringLenBetween(1, 128),
 			},
-			"status": {
-				Type:     schema.TypeString,
-				Computed: true,
+		},
+		"identity_hash": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"last_modified_date": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"message_action": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice(cognitoidentityprovider.MessageActionType_Values(), false),
+		},
+		"max_retry_duration": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: verify.ValidDuration,
+		},
+		"mfa_enrolled_at": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"mfa_fallback_order": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"mfa_setting_list": {
+			Type: schema.TypeSet,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
 			},
-			"sub": {
-				Type:     schema.TypeString,
-				Computed: true,
+			Computed: true,
+		},
+		"phone_number_verified": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Computed: true,
+		},
+		"preferred_mfa_setting": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"user_pool_id": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"username": {
+			Type:             schema.TypeString,
+			Required:         true,
+			ForceNew:         true,
+			ValidateFunc:     validation.StringLenBetween(1, 128),
+			DiffSuppressFunc: userUsernameDiffSuppress,
+		},
+		"username_case_insensitive": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"username_resolved": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"user_reference": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"sensitive_attributes": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"sensitive_attributes_hash": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"seeded_attributes": {
+			Type:     schema.TypeMap,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"standard_attributes": {
+			Type:     schema.TypeMap,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"mfa_options": {
+			Type:          schema.TypeList,
+			Optional:      true,
+			Computed:      true,
+			ConflictsWith: []string{"sms_mfa_settings", "software_token_mfa_settings"},
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"attribute_name": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"delivery_medium": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: validation.StringInSlice(cognitoidentityprovider.DeliveryMediumType_Values(), false),
+					},
+				},
 			},
-			"password": {
-				Type:          schema.TypeString,
-				Sensitive:     true,
-				Optional:      true,
-				ValidateFunc:  validation.StringLenBetween(6, 256),
-				ConflictsWith: []string{"temporary_password"},
+		},
+		"sms_mfa_settings": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Computed: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"enabled": {
+						Type:     schema.TypeBool,
+						Optional: true,
+					},
+					"preferred": {
+						Type:     schema.TypeBool,
+						Optional: true,
+					},
+				},
 			},
-			"temporary_password": {
-				Type:          schema.TypeString,
-				Sensitive:     true,
-				Optional:      true,
-				ValidateFunc:  validation.StringLenBetween(6, 256),
-				ConflictsWith: []string{"password"},
+		},
+		"software_token_mfa_settings": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Computed: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"enabled": {
+						Type:     schema.TypeBool,
+						Optional: true,
+					},
+					"preferred": {
+						Type:     schema.TypeBool,
+						Optional: true,
+					},
+				},
 			},
-			"validation_data": {
-				Type: schema.TypeMap,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+		},
+		"status": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"strict_message_action": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"sub": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"password": {
+			Type:          schema.TypeString,
+			Sensitive:     true,
+			Optional:      true,
+			ValidateFunc:  validation.StringLenBetween(6, 256),
+			ConflictsWith: []string{"temporary_password"},
+		},
+		"password_permanent": {
+			Type:          schema.TypeBool,
+			Optional:      true,
+			ConflictsWith: []string{"temporary_password"},
+		},
+		"password_reset_required": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"raw_attribute_names": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"temporary_password": {
+			Type:          schema.TypeString,
+			Sensitive:     true,
+			Optional:      true,
+			ValidateFunc:  validation.StringLenBetween(6, 256),
+			ConflictsWith: []string{"password", "password_permanent"},
+			Deprecated:    "Use password with password_permanent set to false instead",
+		},
+		"user_attributes": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"value": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
 				},
-				Optional: true,
 			},
 		},
+		"verify_create": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"wait_for_attribute_propagation": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"validation_data": {
+			Type: schema.TypeMap,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+			Optional: true,
+		},
+	}
+}
+
+func resourceUserCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if v, ok := d.GetOk("allowed_attribute_keys"); ok && v.(*schema.Set).Len() > 0 {
+		attributes, err := expandUserConfiguredAttributes(d.Get("attributes_document").(string), d.Get("attributes_json").(string), userAttributesFromConfig(d.Get("attributes").(map[string]interface{}), d.Get("attribute").(*schema.Set)))
+
+		if err != nil {
+			return err
+		}
+
+		if keys := userAttributeKeysNotAllowed(attributes, flex.ExpandStringValueSet(v.(*schema.Set))); len(keys) > 0 {
+			return fmt.Errorf("attributes not in allowed_attribute_keys: %s", strings.Join(keys, ", "))
+		}
+	}
+
+	if keys := userAttributeKeysWithTagPrefix(userAttributesFromConfig(d.Get("attributes").(map[string]interface{}), d.Get("attribute").(*schema.Set))); len(keys) > 0 {
+		return fmt.Errorf("attributes use the %q prefix reserved for tags: %s", userTagAttributePrefix, strings.Join(keys, ", "))
 	}
+
+	if err := userMFASettingsError(d.Get("sms_mfa_settings").([]interface{}), d.Get("software_token_mfa_settings").([]interface{})); err != nil {
+		return err
+	}
+
+	if d.NewValueKnown("attributes_json") {
+		if _, err := mergeUserAttributesJSON(d.Get("attributes_json").(string), userAttributesFromConfig(d.Get("attributes").(map[string]interface{}), d.Get("attribute").(*schema.Set))); err != nil {
+			return err
+		}
+	}
+
+	// Sensitive attribute values are kept out of the plan, so changes to them are planned via their hash.
+	if sensitive := flex.ExpandStringValueSet(d.Get("sensitive_attributes").(*schema.Set)); len(sensitive) > 0 || d.Get("sensitive_attributes_hash").(string) != "" {
+		if rawAttributes := d.GetRawConfig().GetAttr("attributes"); !rawAttributes.IsWhollyKnown() {
+			if err := d.SetNewComputed("sensitive_attributes_hash"); err != nil {
+				return err
+			}
+		} else if hash := userSensitiveAttributesHash(expandUserSensitiveAttributes(rawAttributes, sensitive)); hash != d.Get("sensitive_attributes_hash").(string) {
+			if err := d.SetNew("sensitive_attributes_hash", hash); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Plan an update whenever a self-registered user is still awaiting confirmation.
+	if d.Get("confirm").(bool) && d.Id() != "" && d.Get("status").(string) == cognitoidentityprovider.UserStatusTypeUnconfirmed {
+		if err := d.SetNew("status", cognitoidentityprovider.UserStatusTypeConfirmed); err != nil {
+			return err
+		}
+
+		if err := d.SetNew("password_reset_required", false); err != nil {
+			return err
+		}
+	}
+
+	// Plan an update whenever the user's status has drifted from desired_status.
+	if v, ok := d.GetOk("desired_status"); ok && d.Id() != "" && d.Get("status").(string) != v.(string) {
+		if err := d.SetNew("status", v.(string)); err != nil {
+			return err
+		}
+
+		if err := d.SetNew("password_reset_required", userPasswordResetRequired(v.(string))); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	conn := meta.(*conns.AWSClient).CognitoIDPConn()
+	conn := userConn(meta)
+
+	// The create deadline covers AdminCreateUser, the follow-up calls and the trailing read.
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
+	retryDeadline := userRetryDeadline(d)
 
 	username := d.Get("username").(string)
 	userPoolId := d.Get("user_pool_id").(string)
+	userPools := make(map[string]*cognitoidentityprovider.UserPoolType)
+
+	defer invalidateUserCache(userPoolId)
 
 	params := &cognitoidentityprovider.AdminCreateUserInput{
 		Username:   aws.String(username),
@@ -169,30 +603,146 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interf
 		params.MessageAction = aws.String(v.(string))
 	}
 
-	if v, ok := d.GetOk("attributes"); ok {
-		attributes := v.(map[string]interface{})
-		params.UserAttributes = expandAttribute(attributes)
+	sensitive := flex.ExpandStringValueSet(d.Get("sensitive_attributes").(*schema.Set))
+
+	attributes, err := expandUserConfiguredAttributes(d.Get("attributes_document").(string), d.Get("attributes_json").(string), userAttributesFromConfig(omitUserSensitiveAttributes(d.Get("attributes").(map[string]interface{}), sensitive), d.Get("attribute").(*schema.Set)))
+	if err != nil {
+		return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): %s", userPoolId, username, err)
+	}
+
+	// The values of sensitive attributes aren't in the plan and are read from the configuration.
+	sensitiveValues := expandUserSensitiveAttributes(d.GetRawConfig().GetAttr("attributes"), sensitive)
+
+	for k, v := range sensitiveValues {
+		attributes[k] = v
+	}
+
+	rawNames := d.Get("raw_attribute_names").(bool)
+
+	for k, v := range expandUserTagAttributes(d.Get("tags").(map[string]interface{})) {
+		attributes[k] = v
+	}
+
+	for _, k := range applyUserVerifiedAttributes(attributes, userConfiguredVerifiedAttributes(d)) {
+		diags = sdkdiag.AppendWarningf(diags, "Cognito User (%[1]s/%[2]s) has both %[3]s and attributes.%[3]s set, the value of %[3]s is used", userPoolId, username, k)
+	}
+
+	for k, v := range userAutoVerifiedAttributes(attributes, attributes, flex.ExpandStringValueSet(d.Get("auto_verify").(*schema.Set))) {
+		attributes[k] = v
+	}
+
+	// Attribute names passed through verbatim can't collide and aren't checked against the user pool's custom attributes.
+	if !rawNames {
+		if collisions := userAttributeKeyCollisions(attributes); len(collisions) > 0 {
+			return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): attribute keys name the same attribute: %s", userPoolId, username, strings.Join(collisions, "; "))
+		}
+
+		if err := validateUserAttributesInSchema(ctx, conn, userPools, userPoolId, attributes); err != nil {
+			return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): %s", userPoolId, username, err)
+		}
+	}
+
+	if v, ok := d.GetOk("groups"); ok {
+		if err := validateUserGroupsExist(ctx, conn, userPoolId, flex.ExpandStringValueSet(v.(*schema.Set))); err != nil {
+			return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): %s", userPoolId, username, err)
+		}
+	}
+
+	if err := normalizeUserPhoneNumberAttribute(attributes, d.Get("default_phone_country").(string)); err != nil {
+		return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): %s", userPoolId, username, err)
+	}
+
+	if keys := userAttributeValuesTooLong(attributes); len(keys) > 0 {
+		return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): attribute values longer than %d characters: %s", userPoolId, username, userAttributeValueMaxLength, strings.Join(keys, ", "))
+	}
+
+	if keys := userImmutableAttributeKeys(attributes); len(keys) > 0 {
+		return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): attributes are assigned by Cognito and can't be set: %s", userPoolId, username, strings.Join(keys, ", "))
+	}
+
+	params.UserAttributes = expandAttribute(attributes, rawNames)
+
+	if d.Get("auto_delivery_medium").(bool) && len(params.DesiredDeliveryMediums) == 0 {
+		if v := userDefaultDeliveryMediums(attributes, d.Get("message_action").(string)); len(v) > 0 {
+			params.DesiredDeliveryMediums = aws.StringSlice(v)
+		}
 	}
 
 	if v, ok := d.GetOk("validation_data"); ok {
 		attributes := v.(map[string]interface{})
 		// aws sdk uses the same type for both validation data and user attributes
 		// https://docs.aws.amazon.com/sdk-for-go/api/service/cognitoidentityprovider/#AdminCreateUserInput
-		params.ValidationData = expandAttribute(attributes)
+		params.ValidationData = expandAttribute(attributes, false)
+	}
+
+	password, permanent := userConfiguredPassword(d)
+
+	if password != "" && !permanent {
+		name := "password"
+		if _, ok := d.GetOk("temporary_password"); ok {
+			name = "temporary_password"
+		}
+
+		if err := validateUserPasswordAgainstPolicy(ctx, conn, userPools, userPoolId, password); err != nil {
+			return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): %s %s", userPoolId, username, name, err)
+		}
+
+		params.TemporaryPassword = aws.String(password)
 	}
 
-	if v, ok := d.GetOk("temporary_password"); ok {
-		params.TemporaryPassword = aws.String(v.(string))
+	_, hasPassword := d.GetOk("password")
+	_, hasTemporaryPassword := d.GetOk("temporary_password")
+	_, hasDesiredStatus := d.GetOk("desired_status")
+
+	if userMessageSuppressedWithoutPassword(d.Get("message_action").(string), hasPassword, hasTemporaryPassword) {
+		const reason = "message_action is SUPPRESS and neither password nor temporary_password is set, so the temporary password generated by Cognito is never sent and the user can't sign in until a password is set"
+
+		if d.Get("strict_message_action").(bool) {
+			return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): %s", userPoolId, username, reason)
+		}
+
+		diags = sdkdiag.AppendWarningf(diags, "Cognito User (%s/%s): %s", userPoolId, username, reason)
+	} else if !hasPassword && !hasTemporaryPassword && !hasDesiredStatus && d.Get("message_action").(string) != cognitoidentityprovider.MessageActionTypeResend {
+		diags = sdkdiag.AppendWarningf(diags, "Cognito User (%s/%s) is created without password or temporary_password. "+
+			"Cognito generates a temporary password and the user's status is %s until they sign in and set a new password.", userPoolId, username, cognitoidentityprovider.UserStatusTypeForceChangePassword)
 	}
 
 	log.Print("[DEBUG] Creating Cognito User")
 
-	resp, err := conn.AdminCreateUserWithContext(ctx, params)
+	// AdminCreateUser and the AdminDisableUser that follows it are always retried on transient errors,
+	// bounded by the create timeout, so that a user configured as disabled isn't left enabled.
+	createRetryDeadline := retryDeadline
+	if createRetryDeadline.IsZero() {
+		createRetryDeadline = time.Now().Add(d.Timeout(schema.TimeoutCreate))
+	}
+
+	outputRaw, err := retryUserOperation(ctx, createRetryDeadline, func() (interface{}, error) {
+		return conn.AdminCreateUserWithContext(ctx, params)
+	})
+	if userCreateAdoptsExisting(err, d.Get("message_action").(string)) {
+		log.Printf("[INFO] Cognito User (%s/%s) already exists, adopting it into state", userPoolId, username)
+		d.SetId(userCreateResourceID(userPoolId, username))
+
+		if d.Get("confirm").(bool) {
+			if err := confirmUserSignUp(ctx, conn, d, createRetryDeadline); err != nil {
+				return sdkdiag.AppendErrorf(diags, "confirming Cognito User (%s) sign-up: %s", d.Id(), err)
+			}
+		}
+
+		return append(diags, resourceUserRead(ctx, d, meta)...)
+	}
 	if err != nil {
 		return sdkdiag.AppendErrorf(diags, "creating Cognito User (%s/%s): %s", userPoolId, username, err)
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s", aws.StringValue(params.UserPoolId), aws.StringValue(resp.User.Username)))
+	resp := outputRaw.(*cognitoidentityprovider.AdminCreateUserOutput)
+	d.SetId(userCreateResourceID(aws.StringValue(params.UserPoolId), aws.StringValue(resp.User.Username)))
+
+	d.Set("sensitive_attributes_hash", userSensitiveAttributesHash(sensitiveValues))
+
+	if d.Get("attribute_merge_strategy").(string) == userAttributeMergeStrategyServerAuthoritative {
+		d.Set("seeded_attributes", omitUserSensitiveAttributes(attributes, sensitive))
+	}
 
 	if v := d.Get("enabled"); !v.(bool) {
 		disableParams := &cognitoidentityprovider.AdminDisableUserInput{
@@ -200,276 +750,2175 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interf
 			UserPoolId: aws.String(d.Get("user_pool_id").(string)),
 		}
 
-		_, err := conn.AdminDisableUserWithContext(ctx, disableParams)
+		_, err := retryUserOperation(ctx, createRetryDeadline, func() (interface{}, error) {
+			return conn.AdminDisableUserWithContext(ctx, disableParams)
+		})
 		if err != nil {
 			return sdkdiag.AppendErrorf(diags, "disabling Cognito User (%s): %s", d.Id(), err)
 		}
+
+		if err := waitUserEnabledState(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), false, d.Timeout(schema.TimeoutCreate)); err != nil {
+			return sdkdiag.AppendErrorf(diags, "waiting for Cognito User (%s) enabled (%t): %s", d.Id(), false, err)
+		}
+	}
+
+	if password != "" && permanent {
+		setPasswordParams := &cognitoidentityprovider.AdminSetUserPasswordInput{
+			Username:   aws.String(d.Get("username").(string)),
+			UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+			Password:   aws.String(password),
+			Permanent:  aws.Bool(true),
+		}
+
+		_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+			return conn.AdminSetUserPasswordWithContext(ctx, setPasswordParams)
+		})
+		if err != nil {
+			return sdkdiag.AppendErrorf(diags, "setting Cognito User's password (%s): %s", d.Id(), err)
+		}
+	}
+
+	// default_groups is only applied at creation, membership is not reconciled afterwards.
+	if v, ok := d.GetOk("default_groups"); ok {
+		if err := addUserToGroups(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), flex.ExpandStringValueSet(v.(*schema.Set)), retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "adding Cognito User (%s) to default groups: %s", d.Id(), err)
+		}
+	}
+
+	if v, ok := d.GetOk("groups"); ok {
+		if err := addUserToGroups(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), flex.ExpandStringValueSet(v.(*schema.Set)), retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "adding Cognito User (%s) to groups: %s", d.Id(), err)
+		}
+	}
+
+	if d.Get("sms_mfa_settings.#").(int) > 0 || d.Get("software_token_mfa_settings.#").(int) > 0 {
+		if err := setUserMFAPreference(ctx, conn, d, retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "setting Cognito User (%s) MFA preference: %s", d.Id(), err)
+		}
+	}
+
+	if v, ok := d.GetOk("mfa_options"); ok && len(v.([]interface{})) > 0 {
+		if err := setUserSettings(ctx, conn, d, retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "setting Cognito User (%s) MFA options: %s", d.Id(), err)
+		}
+	}
+
+	if _, err := waitUserCreated(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), d.Timeout(schema.TimeoutCreate)); err != nil {
+		return sdkdiag.AppendErrorf(diags, "waiting for Cognito User (%s) create: %s", d.Id(), err)
+	}
+
+	if d.Get("confirm").(bool) {
+		if err := confirmUserSignUp(ctx, conn, d, retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "confirming Cognito User (%s) sign-up: %s", d.Id(), err)
+		}
+	}
+
+	if v, ok := d.GetOk("desired_status"); ok {
+		if err := reconcileUserStatus(ctx, conn, d, v.(string), retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "setting Cognito User (%s) status: %s", d.Id(), err)
+		}
+	}
+
+	if d.Get("verify_create").(bool) {
+		if err := waitUserCreateVerified(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), attributes, rawNames, d.Get("enabled").(bool), d.Get("desired_status").(string)); err != nil {
+			return sdkdiag.AppendErrorf(diags, "verifying Cognito User (%s) create: %s", d.Id(), err)
+		}
+	}
+
+	return append(diags, resourceUserRead(ctx, d, meta)...)
+}
+
+func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := userConn(meta)
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	user, err := FindUserByTwoPartKey(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string))
+
+	if !d.IsNewResource() && tfresource.NotFound(err) {
+		create.LogNotFoundRemoveState(names.CognitoIDP, create.ErrActionReading, ResNameUser, d.Get("username").(string))
+		d.SetId("")
+		return diags
+	}
+
+	if err != nil {
+		return create.DiagError(names.CognitoIDP, create.ErrActionReading, ResNameUser, d.Get("username").(string), err)
+	}
+
+	// The user pool is only read when the usernames differ in case alone, as only then does its case sensitivity matter.
+	if username := d.Get("username").(string); username != aws.StringValue(user.Username) && strings.EqualFold(username, aws.StringValue(user.Username)) {
+		if userPool, err := FindUserPoolByID(ctx, conn, d.Get("user_pool_id").(string)); err != nil {
+			log.Printf("[WARN] Unable to read Cognito User Pool (%s), username case differences are not suppressed: %s", d.Get("user_pool_id").(string), err)
+		} else {
+			d.Set("username_case_insensitive", !userPoolUsernameCaseSensitive(userPool))
+		}
+	}
+
+	// AdminGetUser can return deleted attributes for a while in large user pools. ListUsers is used to cross-check.
+	if d.Get("consistent_read").(bool) {
+		if listed, err := FindUserBySub(ctx, conn, d.Get("user_pool_id").(string), retrieveUserSub(user.UserAttributes)); err != nil {
+			log.Printf("[WARN] Unable to list Cognito User (%s), attributes are not cross-checked: %s", d.Id(), err)
+		} else {
+			user.UserAttributes = reconcileUserAttributes(user.UserAttributes, listed.Attributes)
+		}
+	}
+
+	attributes := flattenUserAttributes(user.UserAttributes, d.Get("raw_attribute_names").(bool))
+
+	d.Set("tags", flattenUserTagAttributes(attributes))
+
+	identities, err := flattenUserIdentities(attributes["identities"])
+	if err != nil {
+		log.Printf("[WARN] Unable to parse Cognito User (%s) identities: %s", d.Id(), err)
+	}
+	if err := d.Set("identities", identities); err != nil {
+		return sdkdiag.AppendErrorf(diags, "setting identities: %s", err)
+	}
+
+	d.Set("email_verified", attributes["email_verified"] == "true")
+	d.Set("phone_number_verified", attributes["phone_number_verified"] == "true")
+
+	// The values of sensitive attributes are kept out of state so that they are redacted in plan output.
+	sensitive := flex.ExpandStringValueSet(d.Get("sensitive_attributes").(*schema.Set))
+	attributes = omitUserSensitiveAttributes(attributes, sensitive)
+
+	standardAttributes, customAttributes := partitionUserAttributes(attributes)
+	d.Set("standard_attributes", standardAttributes)
+	d.Set("custom_attributes", customAttributes)
+
+	// With attribute blocks, only the configured attributes are tracked and the attributes map is left empty.
+	if v := d.Get("attribute").(*schema.Set); v.Len() > 0 {
+		if err := d.Set("attribute", flattenUserAttributeBlocks(attributes, expandUserAttributeBlocks(v))); err != nil {
+			return sdkdiag.AppendErrorf(diags, "setting attribute: %s", err)
+		}
+
+		attributes = map[string]interface{}{}
+	}
+
+	// Attributes that are only set via attributes_document or attributes_json are not tracked in the attributes map.
+	for _, document := range []string{d.Get("attributes_document").(string), d.Get("attributes_json").(string)} {
+		if v, err := expandUserAttributesDocument(document); err == nil {
+			configured := d.Get("attributes").(map[string]interface{})
+
+			for k := range v {
+				if _, ok := configured[k]; !ok {
+					delete(attributes, k)
+				}
+			}
+		}
+	}
+
+	if err := d.Set("attributes", attributes); err != nil {
+		return sdkdiag.AppendErrorf(diags, "setting user attributes (%s): %s", d.Id(), err)
+	}
+
+	if err := d.Set("mfa_setting_list", user.UserMFASettingList); err != nil {
+		return sdkdiag.AppendErrorf(diags, "setting user's mfa settings (%s): %s", d.Id(), err)
+	}
+
+	d.Set("mfa_fallback_order", userMFAFallbackOrder(user.UserMFASettingList, user.PreferredMfaSetting))
+	d.Set("preferred_mfa_setting", user.PreferredMfaSetting)
+	if err := d.Set("mfa_options", flattenUserMFAOptions(user.MFAOptions)); err != nil {
+		return sdkdiag.AppendErrorf(diags, "setting mfa_options: %s", err)
+	}
+	d.Set("sms_mfa_settings", flattenUserMFASettings(cognitoidentityprovider.ChallengeNameTypeSmsMfa, user.UserMFASettingList, user.PreferredMfaSetting))
+	d.Set("software_token_mfa_settings", flattenUserMFASettings(cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa, user.UserMFASettingList, user.PreferredMfaSetting))
+
+	// mfa_enrolled_at is only recorded when the MFA preference is changed, see setUserMFAPreference.
+	if len(user.UserMFASettingList) == 0 {
+		d.Set("mfa_enrolled_at", nil)
+	}
+	d.Set("status", user.UserStatus)
+	d.Set("password_reset_required", userPasswordResetRequired(aws.StringValue(user.UserStatus)))
+	d.Set("enabled", user.Enabled)
+	d.Set("creation_date", user.UserCreateDate.Format(time.RFC3339))
+	d.Set("last_modified_date", user.UserLastModifiedDate.Format(time.RFC3339))
+	d.Set("sub", retrieveUserSub(user.UserAttributes))
+	// The username Cognito returns may differ from the configured one, e.g. in case or when the user was read by an alias.
+	d.Set("username_resolved", user.Username)
+	d.Set("user_reference", userCreateResourceID(d.Get("user_pool_id").(string), aws.StringValue(user.Username)))
+
+	if err := d.Set("user_attributes", flattenUserAttributeList(omitUserAttributes(user.UserAttributes, sensitive))); err != nil {
+		return sdkdiag.AppendErrorf(diags, "setting user_attributes: %s", err)
+	}
+	d.Set("identity_hash", userIdentityHash(d.Get("user_pool_id").(string), retrieveUserSub(user.UserAttributes)))
+
+	// Group membership is only read for users that manage it, so that other users don't need cognito-idp:AdminListGroupsForUser.
+	if d.Get("groups").(*schema.Set).Len() > 0 {
+		groups, err := FindUserGroupNames(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string))
+
+		if err != nil {
+			return sdkdiag.AppendErrorf(diags, "reading Cognito User (%s) groups: %s", d.Id(), err)
+		}
+
+		d.Set("groups", groups)
+	}
+
+	return diags
+}
+
+func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := userConn(meta)
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+
+	defer invalidateUserCache(d.Get("user_pool_id").(string))
+
+	retryDeadline := userRetryDeadline(d)
+
+	log.Println("[DEBUG] Updating Cognito User")
+
+	// Only the server_authoritative strategy tracks the attribute values last written by Terraform.
+	serverAuthoritative := d.Get("attribute_merge_strategy").(string) == userAttributeMergeStrategyServerAuthoritative
+	sensitive := flex.ExpandStringValueSet(d.Get("sensitive_attributes").(*schema.Set))
+
+	switch {
+	case !serverAuthoritative:
+		d.Set("seeded_attributes", nil)
+	case d.HasChange("attribute_merge_strategy"):
+		// The configured attributes were written by Terraform under the previous strategy.
+		seeded, err := expandUserConfiguredAttributes(d.Get("attributes_document").(string), d.Get("attributes_json").(string), userAttributesFromConfig(omitUserSensitiveAttributes(d.Get("attributes").(map[string]interface{}), sensitive), d.Get("attribute").(*schema.Set)))
+		if err != nil {
+			return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): %s", d.Id(), err)
+		}
+		d.Set("seeded_attributes", seeded)
+	}
+
+	if d.HasChanges("attribute", "attributes", "attributes_document", "attributes_json", "email_verified", "phone_number_verified", "sensitive_attributes", "sensitive_attributes_hash", "tags") {
+		oldDocument, newDocument := d.GetChange("attributes_document")
+		oldJSON, newJSON := d.GetChange("attributes_json")
+		oldAttributes, newAttributes := d.GetChange("attributes")
+		oldBlocks, newBlocks := d.GetChange("attribute")
+		// Attributes that are or were sensitive are compared separately.
+		o, _ := d.GetChange("sensitive_attributes")
+		allSensitive := append(flex.ExpandStringValueSet(o.(*schema.Set)), sensitive...)
+
+		old, err := expandUserConfiguredAttributes(oldDocument.(string), oldJSON.(string), userAttributesFromConfig(omitUserSensitiveAttributes(oldAttributes.(map[string]interface{}), allSensitive), oldBlocks.(*schema.Set)))
+		if err != nil {
+			return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): %s", d.Id(), err)
+		}
+
+		new, err := expandUserConfiguredAttributes(newDocument.(string), newJSON.(string), userAttributesFromConfig(omitUserSensitiveAttributes(newAttributes.(map[string]interface{}), sensitive), newBlocks.(*schema.Set)))
+		if err != nil {
+			return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): %s", d.Id(), err)
+		}
+
+		oldTags, newTags := d.GetChange("tags")
+
+		rawNames := d.Get("raw_attribute_names").(bool)
+
+		for k, v := range expandUserTagAttributes(oldTags.(map[string]interface{})) {
+			old[k] = v
+		}
+
+		for k, v := range expandUserTagAttributes(newTags.(map[string]interface{})) {
+			new[k] = v
+		}
+
+		// Compare the configured verified booleans with their prior values rather than with the attributes map.
+		verified := userConfiguredVerifiedAttributes(d)
+		oldVerified := make(map[string]bool, len(verified))
+
+		for k := range verified {
+			o, _ := d.GetChange(k)
+			oldVerified[k] = o.(bool)
+		}
+
+		applyUserVerifiedAttributes(old, oldVerified)
+
+		for _, k := range applyUserVerifiedAttributes(new, verified) {
+			diags = sdkdiag.AppendWarningf(diags, "Cognito User (%[1]s) has both %[2]s and attributes.%[2]s set, the value of %[2]s is used", d.Id(), k)
+		}
+
+		if !rawNames {
+			if collisions := userAttributeKeyCollisions(new); len(collisions) > 0 {
+				return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): attribute keys name the same attribute: %s", d.Id(), strings.Join(collisions, "; "))
+			}
+		}
+
+		upd, del := computeUserAttributesUpdate(old, new)
+
+		// Sensitive attribute values aren't in state, so they are sent whenever they or the sensitive attribute keys change.
+		sensitiveValues := expandUserSensitiveAttributes(d.GetRawConfig().GetAttr("attributes"), sensitive)
+
+		if d.HasChanges("sensitive_attributes", "sensitive_attributes_hash") {
+			for k, v := range sensitiveValues {
+				upd[k] = v
+			}
+
+			for _, k := range userSensitiveAttributesRemoved(allSensitive, sensitiveValues, new) {
+				del = append(del, aws.String(k))
+			}
+		}
+
+		for k, v := range userAutoVerifiedAttributes(upd, new, flex.ExpandStringValueSet(d.Get("auto_verify").(*schema.Set))) {
+			upd[k] = v
+		}
+
+		if !rawNames {
+			if err := validateUserAttributesInSchema(ctx, conn, make(map[string]*cognitoidentityprovider.UserPoolType), d.Get("user_pool_id").(string), upd); err != nil {
+				return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): %s", d.Id(), err)
+			}
+		}
+
+		if err := normalizeUserPhoneNumberAttribute(upd, d.Get("default_phone_country").(string)); err != nil {
+			return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): %s", d.Id(), err)
+		}
+
+		if keys := userAttributeValuesTooLong(upd); len(keys) > 0 {
+			return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): attribute values longer than %d characters: %s", d.Id(), userAttributeValueMaxLength, strings.Join(keys, ", "))
+		}
+
+		if keys := userImmutableAttributeKeys(upd); len(keys) > 0 {
+			return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): attributes are assigned by Cognito and can't be set: %s", d.Id(), strings.Join(keys, ", "))
+		}
+
+		update := func(batch map[string]interface{}) error {
+			params := &cognitoidentityprovider.AdminUpdateUserAttributesInput{
+				Username:       aws.String(d.Get("username").(string)),
+				UserPoolId:     aws.String(d.Get("user_pool_id").(string)),
+				UserAttributes: expandAttribute(batch, rawNames),
+			}
+
+			if v, ok := d.GetOk("client_metadata"); ok {
+				metadata := v.(map[string]interface{})
+				params.ClientMetadata = expandUserClientMetadata(metadata)
+			}
+
+			_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+				return conn.AdminUpdateUserAttributesWithContext(ctx, params)
+			})
+
+			return err
+		}
+		remove := func(names []*string) error {
+			params := &cognitoidentityprovider.AdminDeleteUserAttributesInput{
+				Username:           aws.String(d.Get("username").(string)),
+				UserPoolId:         aws.String(d.Get("user_pool_id").(string)),
+				UserAttributeNames: expandUserAttributesDelete(names, rawNames),
+			}
+
+			_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+				return conn.AdminDeleteUserAttributesWithContext(ctx, params)
+			})
+
+			return err
+		}
+
+		batches := userAttributeUpdateBatches(upd, flex.ExpandStringValueList(d.Get("attribute_apply_order").([]interface{})))
+		applied, err := applyUserAttributesUpdate(batches, del, update, remove)
+
+		if err != nil {
+			err = userAliasExistsError(err, upd)
+			diags = sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): %s", d.Id(), err)
+
+			if len(applied) == 0 {
+				return diags
+			}
+
+			// Persist the attributes that were updated before the failure so that they aren't sent again.
+			if serverAuthoritative {
+				seeded := d.Get("seeded_attributes").(map[string]interface{})
+				for k, v := range omitUserSensitiveAttributes(applied, sensitive) {
+					seeded[k] = v
+				}
+				d.Set("seeded_attributes", seeded)
+			}
+
+			return append(diags, resourceUserRead(ctx, d, meta)...)
+		}
+
+		d.Set("sensitive_attributes_hash", userSensitiveAttributesHash(sensitiveValues))
+
+		if serverAuthoritative {
+			seeded := d.Get("seeded_attributes").(map[string]interface{})
+			for k, v := range omitUserSensitiveAttributes(upd, sensitive) {
+				seeded[k] = v
+			}
+			for _, v := range del {
+				delete(seeded, aws.StringValue(v))
+			}
+			d.Set("seeded_attributes", seeded)
+		}
+
+		if d.Get("wait_for_attribute_propagation").(bool) {
+			if err := waitUserAttributesPropagated(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), expandAttribute(upd, rawNames), expandUserAttributesDelete(del, rawNames), d.Timeout(schema.TimeoutUpdate)); err != nil {
+				return sdkdiag.AppendErrorf(diags, "waiting for Cognito User (%s) attributes to propagate: %s", d.Id(), err)
+			}
+		}
+	}
+
+	if d.HasChange("enabled") {
+		enabled := d.Get("enabled").(bool)
+
+		if enabled {
+			enableParams := &cognitoidentityprovider.AdminEnableUserInput{
+				Username:   aws.String(d.Get("username").(string)),
+				UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+			}
+			_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+				return conn.AdminEnableUserWithContext(ctx, enableParams)
+			})
+			if err != nil {
+				return sdkdiag.AppendErrorf(diags, "enabling Cognito User (%s): %s", d.Id(), err)
+			}
+		} else {
+			disableParams := &cognitoidentityprovider.AdminDisableUserInput{
+				Username:   aws.String(d.Get("username").(string)),
+				UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+			}
+			_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+				return conn.AdminDisableUserWithContext(ctx, disableParams)
+			})
+			if err != nil {
+				return sdkdiag.AppendErrorf(diags, "disabling Cognito User (%s): %s", d.Id(), err)
+			}
+		}
+
+		if err := waitUserEnabledState(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), enabled, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return sdkdiag.AppendErrorf(diags, "waiting for Cognito User (%s) enabled (%t): %s", d.Id(), enabled, err)
+		}
+	}
+
+	if d.HasChange("temporary_password") {
+		password := d.Get("temporary_password").(string)
+
+		if password != "" {
+			setPasswordParams := &cognitoidentityprovider.AdminSetUserPasswordInput{
+				Username:   aws.String(d.Get("username").(string)),
+				UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+				Password:   aws.String(password),
+				Permanent:  aws.Bool(false),
+			}
+
+			_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+				return conn.AdminSetUserPasswordWithContext(ctx, setPasswordParams)
+			})
+			if err != nil {
+				return sdkdiag.AppendErrorf(diags, "changing Cognito User's temporary password (%s): %s", d.Id(), err)
+			}
+		} else {
+			d.Set("temporary_password", nil)
+		}
+	}
+
+	if d.HasChanges("password", "password_permanent") {
+		password := d.Get("password").(string)
+
+		if password != "" {
+			_, permanent := userConfiguredPassword(d)
+
+			setPasswordParams := &cognitoidentityprovider.AdminSetUserPasswordInput{
+				Username:   aws.String(d.Get("username").(string)),
+				UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+				Password:   aws.String(password),
+				Permanent:  aws.Bool(permanent),
+			}
+
+			_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+				return conn.AdminSetUserPasswordWithContext(ctx, setPasswordParams)
+			})
+			if err != nil {
+				return sdkdiag.AppendErrorf(diags, "changing Cognito User's password (%s): %s", d.Id(), err)
+			}
+		} else {
+			d.Set("password", nil)
+		}
+	}
+
+	// force_password_reset is only acted on when it changes to true, Cognito doesn't report reset state.
+	if d.HasChange("force_password_reset") && d.Get("force_password_reset").(bool) {
+		input := &cognitoidentityprovider.AdminResetUserPasswordInput{
+			UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+			Username:   aws.String(d.Get("username").(string)),
+		}
+
+		if v, ok := d.GetOk("client_metadata"); ok {
+			input.ClientMetadata = expandUserClientMetadata(v.(map[string]interface{}))
+		}
+
+		_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+			return conn.AdminResetUserPasswordWithContext(ctx, input)
+		})
+
+		if err != nil {
+			return sdkdiag.AppendErrorf(diags, "resetting Cognito User (%s) password: %s", d.Id(), err)
+		}
+	}
+
+	if d.HasChanges("sms_mfa_settings", "software_token_mfa_settings") {
+		if err := setUserMFAPreference(ctx, conn, d, retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "setting Cognito User (%s) MFA preference: %s", d.Id(), err)
+		}
+	}
+
+	if d.HasChange("mfa_options") {
+		if err := setUserSettings(ctx, conn, d, retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "setting Cognito User (%s) MFA options: %s", d.Id(), err)
+		}
+	}
+
+	if d.HasChange("groups") {
+		o, n := d.GetChange("groups")
+		os, ns := o.(*schema.Set), n.(*schema.Set)
+
+		if err := validateUserGroupsExist(ctx, conn, d.Get("user_pool_id").(string), flex.ExpandStringValueSet(ns.Difference(os))); err != nil {
+			return sdkdiag.AppendErrorf(diags, "adding Cognito User (%s) to groups: %s", d.Id(), err)
+		}
+
+		if err := removeUserFromGroups(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), flex.ExpandStringValueSet(os.Difference(ns)), retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "removing Cognito User (%s) from groups: %s", d.Id(), err)
+		}
+
+		if err := addUserToGroups(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), flex.ExpandStringValueSet(ns.Difference(os)), retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "adding Cognito User (%s) to groups: %s", d.Id(), err)
+		}
+	}
+
+	if d.Get("confirm").(bool) && d.HasChanges("confirm", "status") {
+		if err := confirmUserSignUp(ctx, conn, d, retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "confirming Cognito User (%s) sign-up: %s", d.Id(), err)
+		}
+	}
+
+	if v, ok := d.GetOk("desired_status"); ok && d.HasChanges("desired_status", "status") {
+		if err := reconcileUserStatus(ctx, conn, d, v.(string), retryDeadline); err != nil {
+			return sdkdiag.AppendErrorf(diags, "setting Cognito User (%s) status: %s", d.Id(), err)
+		}
+	}
+
+	// global_sign_out is a trigger: the user is signed out whenever it changes to a new non-empty value.
+	// The previous value is kept in state until the sign out succeeds, so that a failed sign out is retried.
+	if d.HasChange("global_sign_out") && d.Get("global_sign_out").(string) != "" {
+		o, _ := d.GetChange("global_sign_out")
+
+		if !d.Get("enabled").(bool) {
+			d.Set("global_sign_out", o)
+			return sdkdiag.AppendErrorf(diags, "signing out Cognito User (%s): user is disabled, set enabled = true or leave global_sign_out unchanged", d.Id())
+		}
+
+		input := &cognitoidentityprovider.AdminUserGlobalSignOutInput{
+			UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+			Username:   aws.String(d.Get("username").(string)),
+		}
+
+		_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+			return conn.AdminUserGlobalSignOutWithContext(ctx, input)
+		})
+
+		if err != nil {
+			d.Set("global_sign_out", o)
+			return sdkdiag.AppendErrorf(diags, "signing out Cognito User (%s): %s", d.Id(), err)
+		}
+	}
+
+	return append(diags, resourceUserRead(ctx, d, meta)...)
+}
+
+// userCreateAdoptsExisting returns whether AdminCreateUser failed because the user whose invitation is being resent
+// already exists, in which case the existing user is adopted rather than created.
+func userCreateAdoptsExisting(err error, messageAction string) bool {
+	return messageAction == cognitoidentityprovider.MessageActionTypeResend && tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeUsernameExistsException)
+}
+
+func resourceUserDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := userConn(meta)
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
+	defer invalidateUserCache(d.Get("user_pool_id").(string))
+
+	log.Printf("[DEBUG] Deleting Cognito User: %s", d.Id())
+	_, err := conn.AdminDeleteUserWithContext(ctx, &cognitoidentityprovider.AdminDeleteUserInput{
+		Username:   aws.String(d.Get("username").(string)),
+		UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+	})
+
+	// ResourceNotFoundException is returned if the user pool itself has been deleted.
+	if tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeUserNotFoundException, cognitoidentityprovider.ErrCodeResourceNotFoundException) {
+		return diags
+	}
+
+	if err != nil {
+		return sdkdiag.AppendErrorf(diags, "deleting Cognito User (%s): %s", d.Id(), err)
+	}
+
+	if err := waitUserDeleted(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string), d.Timeout(schema.TimeoutDelete)); err != nil {
+		return sdkdiag.AppendErrorf(diags, "waiting for Cognito User (%s) delete: %s", d.Id(), err)
+	}
+
+	return diags
+}
+
+func resourceUserImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	userPoolId, name, err := userParseImportID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(userCreateResourceID(userPoolId, name))
+	d.Set("user_pool_id", userPoolId)
+	d.Set("username", name)
+	d.Set("attribute_merge_strategy", userAttributeMergeStrategyConfigAuthoritative)
+	d.Set("auto_delivery_medium", false)
+	d.Set("confirm", false)
+	d.Set("consistent_read", false)
+	d.Set("force_password_reset", false)
+	d.Set("verify_create", false)
+	d.Set("wait_for_attribute_propagation", false)
+	return []*schema.ResourceData{d}, nil
+}
+
+// userParseImportID splits an import ID of the form user_pool_id/username or user_pool_id:username.
+// Only the first separator is significant, so usernames may contain either character.
+// The username is only URL-decoded if the ID is one created by userCreateResourceID, otherwise it's used literally.
+func userParseImportID(id string) (string, string, error) {
+	i := strings.IndexAny(id, "/:")
+	if i <= 0 || i == len(id)-1 {
+		return "", "", fmt.Errorf("unexpected format for ID (%s), expected user_pool_id/username or user_pool_id:username", id)
+	}
+
+	userPoolID, username := id[:i], id[i+1:]
+
+	if id[i] == '/' {
+		if _, v, err := userParseResourceID(id); err == nil && userCreateResourceID(userPoolID, v) == id {
+			username = v
+		}
+	}
+
+	return userPoolID, username, nil
+}
+
+// userCreateResourceID returns the resource ID of a user.
+// The username is URL-encoded as it may contain the "/" separator.
+func userCreateResourceID(userPoolID, username string) string {
+	return userPoolID + "/" + url.PathEscape(username)
+}
+
+// userParseResourceID parses a resource ID created by userCreateResourceID.
+func userParseResourceID(id string) (string, string, error) {
+	userPoolID, encodedUsername, ok := strings.Cut(id, "/")
+
+	if !ok || userPoolID == "" || encodedUsername == "" {
+		return "", "", fmt.Errorf("unexpected format for ID (%s), expected user_pool_id/username", id)
+	}
+
+	username, err := url.PathUnescape(encodedUsername)
+
+	if err != nil {
+		return "", "", fmt.Errorf("unexpected format for ID (%s): %w", id, err)
+	}
+
+	return userPoolID, username, nil
+}
+
+func FindUserByTwoPartKey(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string) (*cognitoidentityprovider.AdminGetUserOutput, error) {
+	input := &cognitoidentityprovider.AdminGetUserInput{
+		Username:   aws.String(username),
+		UserPoolId: aws.String(userPoolID),
+	}
+
+	output, err := conn.AdminGetUserWithContext(ctx, input)
+
+	if tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeUserNotFoundException, cognitoidentityprovider.ErrCodeResourceNotFoundException) {
+		return nil, &resource.NotFoundError{
+			LastError:   err,
+			LastRequest: input,
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if output == nil {
+		return nil, tfresource.NewEmptyResultError(input)
+	}
+
+	return output, nil
+}
+
+// userConn returns the Cognito IDP connection used by the user resource and data source.
+// It's a variable so that tests can stub the connection, e.g. with a client for a local endpoint.
+var userConn = func(meta interface{}) *cognitoidentityprovider.CognitoIdentityProvider {
+	return meta.(*conns.AWSClient).CognitoIDPConn()
+}
+
+// userCache caches users, keyed by user pool ID and username, so that data sources reading the same user
+// during a plan or apply call AdminGetUser once. The resource invalidates the cache after any write.
+var userCache sync.Map
+
+func userCacheKey(userPoolID, username string) string {
+	return userPoolID + "/" + username
+}
+
+func findUserByTwoPartKeyCached(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string) (*cognitoidentityprovider.AdminGetUserOutput, error) {
+	key := userCacheKey(userPoolID, username)
+
+	if v, ok := userCache.Load(key); ok {
+		return v.(*cognitoidentityprovider.AdminGetUserOutput), nil
+	}
+
+	user, err := FindUserByTwoPartKey(ctx, conn, userPoolID, username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userCache.Store(key, user)
+
+	return user, nil
+}
+
+// invalidateUserCache removes the cached users of the user pool.
+// All of the pool's users are removed as a user can be read by an alias other than its username.
+func invalidateUserCache(userPoolID string) {
+	prefix := userCacheKey(userPoolID, "")
+
+	userCache.Range(func(k, _ interface{}) bool {
+		if strings.HasPrefix(k.(string), prefix) {
+			userCache.Delete(k)
+		}
+
+		return true
+	})
+}
+
+// addUserToGroups adds the user to each of the groups.
+// AdminAddUserToGroup succeeds if the user is already a member of the group.
+func addUserToGroups(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string, groups []string, retryDeadline time.Time) error {
+	for _, group := range groups {
+		input := &cognitoidentityprovider.AdminAddUserToGroupInput{
+			GroupName:  aws.String(group),
+			UserPoolId: aws.String(userPoolID),
+			Username:   aws.String(username),
+		}
+
+		_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+			return conn.AdminAddUserToGroupWithContext(ctx, input)
+		})
+
+		if err != nil {
+			return fmt.Errorf("adding to group (%s): %w", group, err)
+		}
+	}
+
+	return nil
+}
+
+// removeUserFromGroups removes the user from each of the groups.
+// Groups that have been deleted are ignored.
+func removeUserFromGroups(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string, groups []string, retryDeadline time.Time) error {
+	for _, group := range groups {
+		input := &cognitoidentityprovider.AdminRemoveUserFromGroupInput{
+			GroupName:  aws.String(group),
+			UserPoolId: aws.String(userPoolID),
+			Username:   aws.String(username),
+		}
+
+		_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+			return conn.AdminRemoveUserFromGroupWithContext(ctx, input)
+		})
+
+		if tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeResourceNotFoundException) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("removing from group (%s): %w", group, err)
+		}
+	}
+
+	return nil
+}
+
+// setUserMFAPreference sets the user's SMS and software token MFA settings.
+// Methods that aren't configured are left unchanged.
+func setUserMFAPreference(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, d *schema.ResourceData, retryDeadline time.Time) error {
+	input := &cognitoidentityprovider.AdminSetUserMFAPreferenceInput{
+		UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+		Username:   aws.String(d.Get("username").(string)),
+	}
+
+	if v, ok := d.GetOk("sms_mfa_settings"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+		tfMap := v.([]interface{})[0].(map[string]interface{})
+		input.SMSMfaSettings = &cognitoidentityprovider.SMSMfaSettingsType{
+			Enabled:      aws.Bool(tfMap["enabled"].(bool)),
+			PreferredMfa: aws.Bool(tfMap["preferred"].(bool)),
+		}
+
+		// Cognito rejects SMS MFA for a user without a verified phone number with an opaque InvalidParameterException.
+		if tfMap["enabled"].(bool) {
+			user, err := FindUserByTwoPartKey(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string))
+
+			if err != nil {
+				return err
+			}
+
+			if err := userSMSMFAPhoneNumberError(user.UserAttributes); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v, ok := d.GetOk("software_token_mfa_settings"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+		tfMap := v.([]interface{})[0].(map[string]interface{})
+		input.SoftwareTokenMfaSettings = &cognitoidentityprovider.SoftwareTokenMfaSettingsType{
+			Enabled:      aws.Bool(tfMap["enabled"].(bool)),
+			PreferredMfa: aws.Bool(tfMap["preferred"].(bool)),
+		}
+	}
+
+	_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+		return conn.AdminSetUserMFAPreferenceWithContext(ctx, input)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Cognito doesn't report when MFA was enrolled, so record the user's last modification
+	// date while the MFA preference change is known to be the most recent one.
+	user, err := FindUserByTwoPartKey(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string))
+
+	if err != nil {
+		return err
+	}
+
+	if v := userMFAEnrolledAt(user); v != "" {
+		d.Set("mfa_enrolled_at", v)
+	} else {
+		d.Set("mfa_enrolled_at", nil)
+	}
+
+	return nil
+}
+
+// userMFAFallbackOrder returns the user's activated MFA methods in the order they are used:
+// the preferred method first, then the remaining methods in the order Cognito lists them.
+func userMFAFallbackOrder(settingList []*string, preferredSetting *string) []string {
+	preferred := aws.StringValue(preferredSetting)
+	order := make([]string, 0, len(settingList))
+
+	for _, v := range settingList {
+		if aws.StringValue(v) == preferred {
+			order = append([]string{preferred}, order...)
+		} else {
+			order = append(order, aws.StringValue(v))
+		}
+	}
+
+	return order
+}
+
+// userMFAEnrolledAt returns the user's last modification date if an MFA method is enabled.
+// It's only meaningful immediately after the user's MFA preference has been changed.
+func userMFAEnrolledAt(user *cognitoidentityprovider.AdminGetUserOutput) string {
+	if user == nil || len(user.UserMFASettingList) == 0 || user.UserLastModifiedDate == nil {
+		return ""
+	}
+
+	return user.UserLastModifiedDate.Format(time.RFC3339)
+}
+
+// userSMSMFAPhoneNumberError returns an error if the user doesn't have the verified phone number that SMS MFA requires.
+func userSMSMFAPhoneNumberError(apiList []*cognitoidentityprovider.AttributeType) error {
+	var phoneNumber, phoneNumberVerified string
+
+	for _, apiObject := range apiList {
+		if apiObject == nil {
+			continue
+		}
+
+		switch aws.StringValue(apiObject.Name) {
+		case "phone_number":
+			phoneNumber = aws.StringValue(apiObject.Value)
+		case "phone_number_verified":
+			phoneNumberVerified = aws.StringValue(apiObject.Value)
+		}
+	}
+
+	if phoneNumber == "" {
+		return errors.New("sms_mfa_settings: enabling SMS MFA requires the user to have a phone_number attribute")
+	}
+
+	if phoneNumberVerified != "true" {
+		return errors.New("sms_mfa_settings: enabling SMS MFA requires the user's phone_number to be verified, e.g., with phone_number_verified set to true")
+	}
+
+	return nil
+}
+
+// userMFASettingsError returns an error if a preferred MFA method isn't enabled or more than one method is preferred.
+func userMFASettingsError(sms, softwareToken []interface{}) error {
+	preferred := 0
+
+	for _, v := range []struct {
+		name   string
+		tfList []interface{}
+	}{
+		{"sms_mfa_settings", sms},
+		{"software_token_mfa_settings", softwareToken},
+	} {
+		name, tfList := v.name, v.tfList
+
+		if len(tfList) == 0 || tfList[0] == nil {
+			continue
+		}
+
+		tfMap := tfList[0].(map[string]interface{})
+
+		if !tfMap["preferred"].(bool) {
+			continue
+		}
+
+		if !tfMap["enabled"].(bool) {
+			return fmt.Errorf("%s: preferred requires enabled to be true", name)
+		}
+
+		preferred++
+	}
+
+	if preferred > 1 {
+		return errors.New("only one of sms_mfa_settings and software_token_mfa_settings can be preferred")
+	}
+
+	return nil
+}
+
+func flattenUserMFASettings(method string, settingList []*string, preferredSetting *string) []interface{} {
+	tfMap := map[string]interface{}{
+		"enabled":   false,
+		"preferred": aws.StringValue(preferredSetting) == method,
+	}
+
+	for _, v := range settingList {
+		if aws.StringValue(v) == method {
+			tfMap["enabled"] = true
+		}
+	}
+
+	return []interface{}{tfMap}
+}
+
+// setUserSettings sets the user's legacy MFA options with AdminSetUserSettings.
+// An empty list removes the user's MFA options.
+func setUserSettings(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, d *schema.ResourceData, retryDeadline time.Time) error {
+	input := &cognitoidentityprovider.AdminSetUserSettingsInput{
+		MFAOptions: expandUserMFAOptions(d.Get("mfa_options").([]interface{})),
+		UserPoolId: aws.String(d.Get("user_pool_id").(string)),
+		Username:   aws.String(d.Get("username").(string)),
+	}
+
+	_, err := retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+		return conn.AdminSetUserSettingsWithContext(ctx, input)
+	})
+
+	return err
+}
+
+func expandUserMFAOptions(tfList []interface{}) []*cognitoidentityprovider.MFAOptionType {
+	apiObjects := make([]*cognitoidentityprovider.MFAOptionType, 0, len(tfList))
+
+	for _, tfMapRaw := range tfList {
+		tfMap, ok := tfMapRaw.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		apiObject := &cognitoidentityprovider.MFAOptionType{
+			DeliveryMedium: aws.String(tfMap["delivery_medium"].(string)),
+		}
+
+		if v, ok := tfMap["attribute_name"].(string); ok && v != "" {
+			apiObject.AttributeName = aws.String(v)
+		}
+
+		apiObjects = append(apiObjects, apiObject)
+	}
+
+	return apiObjects
+}
+
+func flattenUserMFAOptions(apiObjects []*cognitoidentityprovider.MFAOptionType) []interface{} {
+	tfList := make([]interface{}, 0, len(apiObjects))
+
+	for _, apiObject := range apiObjects {
+		if apiObject == nil {
+			continue
+		}
+
+		tfList = append(tfList, map[string]interface{}{
+			"attribute_name":  aws.StringValue(apiObject.AttributeName),
+			"delivery_medium": aws.StringValue(apiObject.DeliveryMedium),
+		})
+	}
+
+	return tfList
+}
+
+// confirmUserSignUp confirms the sign-up of a self-registered user that is UNCONFIRMED.
+func confirmUserSignUp(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, d *schema.ResourceData, retryDeadline time.Time) error {
+	userPoolID, username := d.Get("user_pool_id").(string), d.Get("username").(string)
+
+	user, err := FindUserByTwoPartKey(ctx, conn, userPoolID, username)
+
+	if err != nil {
+		return err
+	}
+
+	if aws.StringValue(user.UserStatus) != cognitoidentityprovider.UserStatusTypeUnconfirmed {
+		return nil
+	}
+
+	input := &cognitoidentityprovider.AdminConfirmSignUpInput{
+		UserPoolId: aws.String(userPoolID),
+		Username:   aws.String(username),
+	}
+
+	if v, ok := d.GetOk("client_metadata"); ok {
+		input.ClientMetadata = expandUserClientMetadata(v.(map[string]interface{}))
+	}
+
+	_, err = retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+		return conn.AdminConfirmSignUpWithContext(ctx, input)
+	})
+
+	return userConfirmSignUpError(err)
+}
+
+// userConfirmSignUpError returns the error from AdminConfirmSignUp, if any.
+// Cognito returns NotAuthorizedException for a user that is already confirmed, which is treated as success.
+func userConfirmSignUpError(err error) error {
+	if tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeNotAuthorizedException) {
+		return nil
+	}
+
+	return err
+}
+
+// reconcileUserStatus moves the user from its current status to the desired status.
+func reconcileUserStatus(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, d *schema.ResourceData, desired string, retryDeadline time.Time) error {
+	userPoolID, username := d.Get("user_pool_id").(string), d.Get("username").(string)
+
+	user, err := FindUserByTwoPartKey(ctx, conn, userPoolID, username)
+
+	if err != nil {
+		return err
+	}
+
+	current := aws.StringValue(user.UserStatus)
+
+	if current == desired {
+		return nil
+	}
+
+	password, permanent := userConfiguredPassword(d)
+
+	if err := userStatusTransitionError(current, desired, password != "" && permanent, password != "" && !permanent); err != nil {
+		return err
+	}
+
+	switch desired {
+	case cognitoidentityprovider.UserStatusTypeConfirmed, cognitoidentityprovider.UserStatusTypeForceChangePassword:
+		input := &cognitoidentityprovider.AdminSetUserPasswordInput{
+			Password:   aws.String(password),
+			Permanent:  aws.Bool(true),
+			UserPoolId: aws.String(userPoolID),
+			Username:   aws.String(username),
+		}
+
+		if desired == cognitoidentityprovider.UserStatusTypeForceChangePassword {
+			input.Permanent = aws.Bool(false)
+		}
+
+		_, err = retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+			return conn.AdminSetUserPasswordWithContext(ctx, input)
+		})
+	case cognitoidentityprovider.UserStatusTypeResetRequired:
+		input := &cognitoidentityprovider.AdminResetUserPasswordInput{
+			UserPoolId: aws.String(userPoolID),
+			Username:   aws.String(username),
+		}
+
+		if v, ok := d.GetOk("client_metadata"); ok {
+			input.ClientMetadata = expandUserClientMetadata(v.(map[string]interface{}))
+		}
+
+		_, err = retryUserOperation(ctx, retryDeadline, func() (interface{}, error) {
+			return conn.AdminResetUserPasswordWithContext(ctx, input)
+		})
+	}
+
+	return err
+}
+
+// userStatusTransitionError returns an error if the user can't be moved from the current to the desired status.
+func userStatusTransitionError(current, desired string, hasPassword, hasTemporaryPassword bool) error {
+	switch desired {
+	case cognitoidentityprovider.UserStatusTypeConfirmed:
+		if !hasPassword {
+			return fmt.Errorf("password must be set, without password_permanent set to false, to move user from status %s to %s", current, desired)
+		}
+	case cognitoidentityprovider.UserStatusTypeForceChangePassword:
+		if !hasTemporaryPassword {
+			return fmt.Errorf("password with password_permanent set to false, or temporary_password, must be set to move user from status %s to %s", current, desired)
+		}
+	case cognitoidentityprovider.UserStatusTypeResetRequired:
+		if current != cognitoidentityprovider.UserStatusTypeConfirmed {
+			return fmt.Errorf("user password can only be reset from status %s, not %s", cognitoidentityprovider.UserStatusTypeConfirmed, current)
+		}
+	default:
+		return fmt.Errorf("unsupported desired status: %s", desired)
+	}
+
+	return nil
+}
+
+// userConfiguredPassword returns the configured password and whether it's set as permanent.
+func userConfiguredPassword(d *schema.ResourceData) (string, bool) {
+	var permanent *bool
+
+	if rawConfig := d.GetRawConfig(); !rawConfig.IsNull() && rawConfig.IsKnown() {
+		if v := rawConfig.GetAttr("password_permanent"); v.IsKnown() && !v.IsNull() {
+			permanent = aws.Bool(v.True())
+		}
+	}
+
+	return expandUserPassword(d.Get("password").(string), d.Get("temporary_password").(string), permanent)
+}
+
+// expandUserPassword maps password, password_permanent and the deprecated temporary_password to a single password.
+// temporary_password is never permanent, and password is permanent unless password_permanent is false.
+func expandUserPassword(password, temporaryPassword string, permanent *bool) (string, bool) {
+	if temporaryPassword != "" {
+		return temporaryPassword, false
+	}
+
+	if permanent == nil {
+		return password, true
+	}
+
+	return password, *permanent
+}
+
+// userPasswordResetRequired returns whether a user with the status must set a new password before signing in.
+func userPasswordResetRequired(status string) bool {
+	return status == cognitoidentityprovider.UserStatusTypeForceChangePassword || status == cognitoidentityprovider.UserStatusTypeResetRequired
+}
+
+// userRetryDeadline returns the deadline for retrying transient errors across all
+// sub-operations of a single create or update, or the zero time if max_retry_duration isn't set.
+func userRetryDeadline(d *schema.ResourceData) time.Time {
+	v, ok := d.GetOk("max_retry_duration")
+
+	if !ok {
+		return time.Time{}
+	}
+
+	duration, err := time.ParseDuration(v.(string))
+
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Now().Add(duration)
+}
+
+// retryUserOperation calls f, retrying transient errors until deadline.
+// With a zero deadline, or once the deadline has passed, f is called once.
+func retryUserOperation(ctx context.Context, deadline time.Time, f func() (interface{}, error)) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if deadline.IsZero() {
+		return f()
+	}
+
+	timeout := time.Until(deadline)
+
+	if timeout <= 0 {
+		return f()
+	}
+
+	output, err := tfresource.RetryWhenAWSErrCodeEquals(ctx, timeout, f, cognitoidentityprovider.ErrCodeInternalErrorException, cognitoidentityprovider.ErrCodeTooManyRequestsException)
+
+	// Report the cancellation, rather than the last transient error, when the resource timeout aborts the retries.
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return nil, fmt.Errorf("%w: %s", ctxErr, err)
+	}
+
+	return output, err
+}
+
+// expandAttribute returns the attributes to send to Cognito.
+// Unless raw is set, attribute keys are converted to the names Cognito uses.
+func expandAttribute(tfMap map[string]interface{}, raw bool) []*cognitoidentityprovider.AttributeType {
+	if len(tfMap) == 0 {
+		return nil
+	}
+
+	apiList := make([]*cognitoidentityprovider.AttributeType, 0, len(tfMap))
+
+	for k, v := range tfMap {
+		apiList = append(apiList, &cognitoidentityprovider.AttributeType{
+			Name:  aws.String(userAttributeName(k, raw)),
+			Value: aws.String(v.(string)),
+		})
+	}
+
+	return apiList
+}
+
+// expandUserAttributesDocument parses a JSON object of user attributes.
+// The object must be flat and every value must be a string.
+func expandUserAttributesDocument(document string) (map[string]interface{}, error) {
+	tfMap := make(map[string]interface{})
+
+	if document == "" {
+		return tfMap, nil
+	}
+
+	var raw map[string]interface{}
+
+	if err := json.Unmarshal([]byte(document), &raw); err != nil {
+		return nil, fmt.Errorf("decoding attributes document: must be a JSON object: %w", err)
+	}
+
+	for k, v := range raw {
+		value, ok := v.(string)
+
+		if !ok {
+			return nil, fmt.Errorf("attributes document key %q: value must be a string, got %T", k, v)
+		}
+
+		tfMap[k] = value
+	}
+
+	return tfMap, nil
+}
+
+// mergeUserAttributes merges the attributes document with the attributes map.
+// Keys set explicitly in the attributes map take precedence.
+func mergeUserAttributes(document string, attributes map[string]interface{}) (map[string]interface{}, error) {
+	tfMap, err := expandUserAttributesDocument(document)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range attributes {
+		tfMap[k] = v
+	}
+
+	return tfMap, nil
+}
+
+// mergeUserAttributesJSON merges the attributes_json object into the attributes.
+// Unlike with attributes_document, a key naming an attribute that is also set in attributes is an error.
+func mergeUserAttributesJSON(attributesJSON string, attributes map[string]interface{}) (map[string]interface{}, error) {
+	v, err := expandUserAttributesDocument(attributesJSON)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(attributes))
+	tfMap := make(map[string]interface{}, len(attributes)+len(v))
+
+	for k, v := range attributes {
+		names[userAttributeAPIName(k)] = struct{}{}
+		tfMap[k] = v
+	}
+
+	var keys []string
+
+	for k, v := range v {
+		if _, ok := names[userAttributeAPIName(k)]; ok {
+			keys = append(keys, k)
+			continue
+		}
+
+		tfMap[k] = v
+	}
+
+	if len(keys) > 0 {
+		sort.Strings(keys)
+
+		return nil, fmt.Errorf("attributes_json keys also set in attributes: %s", strings.Join(keys, ", "))
+	}
+
+	return tfMap, nil
+}
+
+// expandUserConfiguredAttributes returns the configured attributes, with attributes_json and then attributes_document merged in.
+func expandUserConfiguredAttributes(document, attributesJSON string, attributes map[string]interface{}) (map[string]interface{}, error) {
+	attributes, err := mergeUserAttributesJSON(attributesJSON, attributes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeUserAttributes(document, attributes)
+}
+
+// userAttributesFromConfig returns the attributes configured with either the attributes map or attribute blocks.
+func userAttributesFromConfig(attributes map[string]interface{}, blocks *schema.Set) map[string]interface{} {
+	tfMap := make(map[string]interface{}, len(attributes)+blocks.Len())
+
+	for k, v := range attributes {
+		tfMap[k] = v
+	}
+
+	for k, v := range expandUserAttributeBlocks(blocks) {
+		tfMap[k] = v
+	}
+
+	return tfMap
+}
+
+// userSensitiveAttributeNames returns the Cognito names of the attribute keys listed in sensitive_attributes.
+func userSensitiveAttributeNames(keys []string) map[string]struct{} {
+	names := make(map[string]struct{}, len(keys))
+
+	for _, k := range keys {
+		names[userAttributeAPIName(k)] = struct{}{}
+	}
+
+	return names
+}
+
+// omitUserSensitiveAttributes returns a copy of the attributes without those listed in sensitive_attributes.
+func omitUserSensitiveAttributes(tfMap map[string]interface{}, keys []string) map[string]interface{} {
+	names := userSensitiveAttributeNames(keys)
+	result := make(map[string]interface{}, len(tfMap))
+
+	for k, v := range tfMap {
+		if _, ok := names[userAttributeAPIName(k)]; !ok {
+			result[k] = v
+		}
+	}
+
+	return result
+}
+
+// expandUserSensitiveAttributes returns the configured values of the attributes listed in sensitive_attributes.
+// Their diffs are suppressed, so the values are read from the raw configuration rather than from the plan.
+func expandUserSensitiveAttributes(rawAttributes cty.Value, keys []string) map[string]interface{} {
+	tfMap := make(map[string]interface{})
+
+	if len(keys) == 0 || rawAttributes.IsNull() || !rawAttributes.IsKnown() {
+		return tfMap
+	}
+
+	names := userSensitiveAttributeNames(keys)
+
+	for it := rawAttributes.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+
+		if _, ok := names[userAttributeAPIName(k.AsString())]; !ok || v.IsNull() || !v.IsKnown() {
+			continue
+		}
+
+		tfMap[k.AsString()] = v.AsString()
+	}
+
+	return tfMap
+}
+
+// userSensitiveAttributesHash returns a hash of the sensitive attribute values, or an empty string if there are none.
+func userSensitiveAttributesHash(tfMap map[string]interface{}) string {
+	if len(tfMap) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tfMap))
+
+	for k := range tfMap {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	hash := sha256.New()
+
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", k, tfMap[k])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// userSensitiveAttributesRemoved returns the sensitive attribute keys that are no longer configured,
+// either as sensitive attributes or as other attributes.
+func userSensitiveAttributesRemoved(keys []string, sensitive, attributes map[string]interface{}) []string {
+	configured := make(map[string]struct{}, len(sensitive)+len(attributes))
+
+	for k := range sensitive {
+		configured[userAttributeAPIName(k)] = struct{}{}
+	}
+
+	for k := range attributes {
+		configured[userAttributeAPIName(k)] = struct{}{}
+	}
+
+	var removed []string
+
+	for _, k := range keys {
+		if _, ok := configured[userAttributeAPIName(k)]; !ok {
+			configured[userAttributeAPIName(k)] = struct{}{}
+			removed = append(removed, k)
+		}
+	}
+
+	sort.Strings(removed)
+
+	return removed
+}
+
+// omitUserAttributes returns the attributes other than those listed in sensitive_attributes.
+func omitUserAttributes(apiList []*cognitoidentityprovider.AttributeType, keys []string) []*cognitoidentityprovider.AttributeType {
+	if len(keys) == 0 {
+		return apiList
+	}
+
+	names := userSensitiveAttributeNames(keys)
+	result := make([]*cognitoidentityprovider.AttributeType, 0, len(apiList))
+
+	for _, apiObject := range apiList {
+		if apiObject == nil {
+			continue
+		}
+
+		if _, ok := names[aws.StringValue(apiObject.Name)]; !ok {
+			result = append(result, apiObject)
+		}
+	}
+
+	return result
+}
+
+// expandUserAttributeBlocks returns the attribute blocks as a map of attribute values keyed by name.
+func expandUserAttributeBlocks(tfSet *schema.Set) map[string]interface{} {
+	tfMap := make(map[string]interface{}, tfSet.Len())
+
+	for _, tfMapRaw := range tfSet.List() {
+		block, ok := tfMapRaw.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		tfMap[block["name"].(string)] = block["value"].(string)
+	}
+
+	return tfMap
+}
+
+// flattenUserAttributeBlocks returns the user's values of the configured attributes as attribute blocks.
+// Configured attributes that the user doesn't have are omitted.
+func flattenUserAttributeBlocks(attributes, configured map[string]interface{}) []interface{} {
+	values := make(map[string]interface{}, len(attributes))
+
+	for k, v := range attributes {
+		values[userAttributeAPIName(k)] = v
+	}
+
+	tfList := make([]interface{}, 0, len(configured))
+
+	for k := range configured {
+		if v, ok := values[userAttributeAPIName(k)]; ok {
+			tfList = append(tfList, map[string]interface{}{
+				"name":  k,
+				"value": v,
+			})
+		}
+	}
+
+	return tfList
+}
+
+// userAttributeAPIName returns the name Cognito uses for a configured attribute key.
+// Non-standard attributes are prefixed with "custom:".
+// Developer-only attributes keep an explicit "dev:" prefix ahead of "custom:".
+func userAttributeAPIName(k string) string {
+	if strings.HasPrefix(k, "dev:") {
+		return "dev:" + userAttributeAPIName(strings.TrimPrefix(k, "dev:"))
+	}
+
+	if !UserAttributeKeyMatchesStandardAttribute(k) && !strings.HasPrefix(k, "custom:") {
+		return fmt.Sprintf("custom:%v", k)
+	}
+
+	return k
+}
+
+// userAttributeName returns the name Cognito uses for a configured attribute key.
+// With raw names the key is used verbatim.
+func userAttributeName(k string, raw bool) string {
+	if raw {
+		return k
+	}
+
+	return userAttributeAPIName(k)
+}
+
+// findUserPoolByIDCached returns the user pool, calling DescribeUserPool only once per user pool for the cache.
+// Callers create the cache for a single create or update so that user pool changes made elsewhere are seen by the next operation.
+func findUserPoolByIDCached(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPools map[string]*cognitoidentityprovider.UserPoolType, userPoolID string) (*cognitoidentityprovider.UserPoolType, error) {
+	if v, ok := userPools[userPoolID]; ok {
+		return v, nil
+	}
+
+	userPool, err := FindUserPoolByID(ctx, conn, userPoolID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userPools[userPoolID] = userPool
+
+	return userPool, nil
+}
+
+// userGroupCache records groups, keyed by user pool ID and group name, that are known to exist so that GetGroup is called once per group during an apply.
+var userGroupCache sync.Map
+
+// validateUserGroupsExist returns an error naming any of the groups that don't exist in the user pool.
+func validateUserGroupsExist(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID string, groups []string) error {
+	var missing []string
+
+	for _, group := range groups {
+		key := userPoolID + "/" + group
+
+		if _, ok := userGroupCache.Load(key); ok {
+			continue
+		}
+
+		_, err := FindGroupByTwoPartKey(ctx, conn, userPoolID, group)
+
+		if tfresource.NotFound(err) {
+			missing = append(missing, group)
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("reading Cognito User Group (%s): %w", group, err)
+		}
+
+		userGroupCache.Store(key, struct{}{})
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+
+		return fmt.Errorf("groups not found in Cognito User Pool (%s): %s", userPoolID, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func findUserPoolSchemaAttributeNames(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPools map[string]*cognitoidentityprovider.UserPoolType, userPoolID string) (map[string]struct{}, error) {
+	userPool, err := findUserPoolByIDCached(ctx, conn, userPools, userPoolID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(userPool.SchemaAttributes))
+
+	for _, v := range userPool.SchemaAttributes {
+		if v != nil {
+			names[aws.StringValue(v.Name)] = struct{}{}
+		}
+	}
+
+	return names, nil
+}
+
+// userPoolUsernameCaseSensitive returns whether the user pool's usernames are case sensitive.
+// User pools without a username configuration are case sensitive.
+func userPoolUsernameCaseSensitive(userPool *cognitoidentityprovider.UserPoolType) bool {
+	if userPool == nil || userPool.UsernameConfiguration == nil || userPool.UsernameConfiguration.CaseSensitive == nil {
+		return true
+	}
+
+	return aws.BoolValue(userPool.UsernameConfiguration.CaseSensitive)
+}
+
+// userUsernameDiffSuppress suppresses username case differences when the user pool is known to be case insensitive.
+// Diff suppression has no API client, so it relies on username_case_insensitive, which Read sets from the user pool.
+func userUsernameDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	if old == new || !strings.EqualFold(old, new) {
+		return false
+	}
+
+	return d.Get("username_case_insensitive").(bool)
+}
+
+// validateUserAttributesInSchema returns an error if a custom attribute isn't declared in the user pool's schema.
+func validateUserAttributesInSchema(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPools map[string]*cognitoidentityprovider.UserPoolType, userPoolID string, tfMap map[string]interface{}) error {
+	// Without custom attributes there's nothing to check.
+	if len(userAttributesNotInSchema(tfMap, nil)) == 0 {
+		return nil
+	}
+
+	names, err := findUserPoolSchemaAttributeNames(ctx, conn, userPools, userPoolID)
+
+	if err != nil {
+		return fmt.Errorf("reading Cognito User Pool (%s) schema: %w", userPoolID, err)
+	}
+
+	if keys := userAttributesNotInSchema(tfMap, names); len(keys) > 0 {
+		return fmt.Errorf("attributes not declared in Cognito User Pool (%s) schema: %s", userPoolID, strings.Join(keys, ", "))
+	}
+
+	return nil
+}
+
+// userAttributesNotInSchema returns the custom attributes, by API name, that aren't in the schema attribute names.
+// Standard attributes aren't checked.
+func userAttributesNotInSchema(tfMap map[string]interface{}, names map[string]struct{}) []string {
+	var keys []string
+
+	for k := range tfMap {
+		if UserAttributeKeyMatchesStandardAttribute(k) {
+			continue
+		}
+
+		name := userAttributeAPIName(k)
+
+		if _, ok := names[name]; !ok {
+			keys = append(keys, name)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// userAttributeValueMaxLength is the maximum length, in characters, of a Cognito user attribute value.
+const userAttributeValueMaxLength = 2048
+
+// userAttributeValuesTooLong returns the attribute keys whose values exceed userAttributeValueMaxLength.
+func userAttributeValuesTooLong(tfMap map[string]interface{}) []string {
+	var keys []string
+
+	for k, v := range tfMap {
+		if utf8.RuneCountInString(v.(string)) > userAttributeValueMaxLength {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// userAttributeKeyCollisions returns the attribute keys that expand to the same Cognito attribute name, e.g. "foo" and "custom:foo".
+// Each collision is described as the attribute name followed by the conflicting keys.
+func userAttributeKeyCollisions(tfMap map[string]interface{}) []string {
+	keysByName := make(map[string][]string)
+
+	for k := range tfMap {
+		name := userAttributeAPIName(k)
+		keysByName[name] = append(keysByName[name], k)
+	}
+
+	var collisions []string
+
+	for name, keys := range keysByName {
+		if len(keys) < 2 {
+			continue
+		}
+
+		sort.Strings(keys)
+		collisions = append(collisions, fmt.Sprintf("%s (%s)", name, strings.Join(keys, ", ")))
+	}
+
+	sort.Strings(collisions)
+
+	return collisions
+}
+
+// userImmutableAttributeKeys returns the attribute keys that name immutable standard attributes.
+func userImmutableAttributeKeys(tfMap map[string]interface{}) []string {
+	var keys []string
+
+	for k := range tfMap {
+		if isImmutableStandardAttribute(userAttributeAPIName(k)) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// userVerifiedAttributes are the standard attributes that can also be set with convenience booleans of the same name.
+var userVerifiedAttributes = []string{
+	"email_verified",
+	"phone_number_verified",
+}
+
+// userConfiguredVerifiedAttributes returns the values of the verified attribute booleans that are set in configuration.
+func userConfiguredVerifiedAttributes(d *schema.ResourceData) map[string]bool {
+	verified := make(map[string]bool)
+	rawConfig := d.GetRawConfig()
+
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return verified
+	}
+
+	for _, k := range userVerifiedAttributes {
+		if v := rawConfig.GetAttr(k); v.IsKnown() && !v.IsNull() {
+			verified[k] = v.True()
+		}
+	}
+
+	return verified
+}
+
+// applyUserVerifiedAttributes sets the verified attributes to "true" or "false" in tfMap, overriding any value from
+// the attributes map. It returns the keys that were already set in tfMap.
+func applyUserVerifiedAttributes(tfMap map[string]interface{}, verified map[string]bool) []string {
+	var overridden []string
+
+	for k, v := range verified {
+		if _, ok := tfMap[k]; ok {
+			overridden = append(overridden, k)
+		}
+
+		tfMap[k] = strconv.FormatBool(v)
+	}
+
+	sort.Strings(overridden)
+
+	return overridden
+}
+
+// userAutoVerifyAttributes are the attributes that can be verified automatically when they are set.
+var userAutoVerifyAttributes = []string{
+	"email",
+	"phone_number",
+}
+
+// userAutoVerifiedAttributes returns the verified attributes, set to "true", for the auto-verified attributes being set in changed.
+// Verified attributes already in configured, e.g. from the email_verified and phone_number_verified booleans, aren't set again.
+func userAutoVerifiedAttributes(changed, configured map[string]interface{}, autoVerify []string) map[string]interface{} {
+	tfMap := make(map[string]interface{})
+
+	for _, k := range autoVerify {
+		if _, ok := changed[k]; !ok {
+			continue
+		}
+
+		if _, ok := configured[k+"_verified"]; ok {
+			continue
+		}
+
+		tfMap[k+"_verified"] = "true"
+	}
+
+	return tfMap
+}
+
+// userTagAttributePrefix is the custom attribute name prefix under which tags are stored.
+const userTagAttributePrefix = "tag_"
+
+// expandUserTagAttributes returns the custom attributes that store the tags.
+// The attributes are keyed by their full names, which are the same with or without raw_attribute_names.
+func expandUserTagAttributes(tags map[string]interface{}) map[string]interface{} {
+	tfMap := make(map[string]interface{}, len(tags))
+
+	for k, v := range tags {
+		tfMap["custom:"+userTagAttributePrefix+k] = v
+	}
+
+	return tfMap
+}
+
+// flattenUserTagAttributes removes the custom attributes that store tags from tfMap and returns the tags.
+func flattenUserTagAttributes(tfMap map[string]interface{}) map[string]interface{} {
+	tags := make(map[string]interface{})
+
+	for k, v := range tfMap {
+		if key := userAttributeKey(k); strings.HasPrefix(key, userTagAttributePrefix) {
+			tags[strings.TrimPrefix(key, userTagAttributePrefix)] = v
+			delete(tfMap, k)
+		}
+	}
+
+	return tags
+}
+
+// userAttributeKeysWithTagPrefix returns the attribute keys that would collide with the custom attributes that store tags.
+func userAttributeKeysWithTagPrefix(tfMap map[string]interface{}) []string {
+	var keys []string
+
+	for k := range tfMap {
+		if strings.HasPrefix(userAttributeAPIName(k), "custom:"+userTagAttributePrefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// userPhoneNumberE164Regexp matches a phone number in E.164 format.
+var userPhoneNumberE164Regexp = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// normalizeUserPhoneNumberAttribute normalizes the phone_number attribute, if any, in place.
+func normalizeUserPhoneNumberAttribute(tfMap map[string]interface{}, defaultCountry string) error {
+	v, ok := tfMap["phone_number"].(string)
+
+	if !ok || v == "" {
+		return nil
+	}
+
+	phoneNumber, err := normalizeUserPhoneNumber(v, defaultCountry)
+
+	if err != nil {
+		return err
+	}
+
+	tfMap["phone_number"] = phoneNumber
+
+	return nil
+}
+
+// normalizeUserPhoneNumber returns the phone number in E.164 format, removing spaces, dashes, dots and parentheses.
+// Numbers without a leading "+" are prefixed with the default country calling code, if one is set.
+func normalizeUserPhoneNumber(v, defaultCountry string) (string, error) {
+	phoneNumber := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, v)
+
+	if !strings.HasPrefix(phoneNumber, "+") && defaultCountry != "" {
+		phoneNumber = "+" + strings.TrimPrefix(defaultCountry, "+") + phoneNumber
+	}
+
+	if !userPhoneNumberE164Regexp.MatchString(phoneNumber) {
+		return "", fmt.Errorf("phone_number %q is not in E.164 format, e.g. +15555550100; set default_phone_country to prefix numbers without a country code", v)
+	}
+
+	return phoneNumber, nil
+}
+
+// userAttributeKeysNotAllowed returns the attribute keys that are not in the allow-list.
+// Keys are compared after "custom:" normalization.
+func userAttributeKeysNotAllowed(tfMap map[string]interface{}, allowed []string) []string {
+	allowedNames := make(map[string]struct{}, len(allowed))
+
+	for _, v := range allowed {
+		allowedNames[userAttributeAPIName(v)] = struct{}{}
+	}
+
+	var keys []string
+
+	for k := range tfMap {
+		if _, ok := allowedNames[userAttributeAPIName(k)]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func expandUserAttributesDelete(input []*string, raw bool) []*string {
+	result := make([]*string, 0, len(input))
+
+	for _, v := range input {
+		result = append(result, aws.String(userAttributeName(aws.StringValue(v), raw)))
 	}
 
-	if v, ok := d.GetOk("password"); ok {
-		setPasswordParams := &cognitoidentityprovider.AdminSetUserPasswordInput{
-			Username:   aws.String(d.Get("username").(string)),
-			UserPoolId: aws.String(d.Get("user_pool_id").(string)),
-			Password:   aws.String(v.(string)),
-			Permanent:  aws.Bool(true),
-		}
+	return result
+}
 
-		_, err := conn.AdminSetUserPasswordWithContext(ctx, setPasswordParams)
-		if err != nil {
-			return sdkdiag.AppendErrorf(diags, "setting Cognito User's password (%s): %s", d.Id(), err)
+// flattenUserAttributeList returns the user's attributes as Cognito reports them, sorted by name.
+// Unlike flattenUserAttributes, names are not normalized.
+func flattenUserAttributeList(apiList []*cognitoidentityprovider.AttributeType) []interface{} {
+	tfList := make([]interface{}, 0, len(apiList))
+
+	for _, apiObject := range apiList {
+		if apiObject == nil || apiObject.Name == nil {
+			continue
 		}
+
+		tfList = append(tfList, map[string]interface{}{
+			"name":  aws.StringValue(apiObject.Name),
+			"value": aws.StringValue(apiObject.Value),
+		})
 	}
 
-	return append(diags, resourceUserRead(ctx, d, meta)...)
+	sort.SliceStable(tfList, func(i, j int) bool {
+		return tfList[i].(map[string]interface{})["name"].(string) < tfList[j].(map[string]interface{})["name"].(string)
+	})
+
+	return tfList
 }
 
-func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	var diags diag.Diagnostics
-	conn := meta.(*conns.AWSClient).CognitoIDPConn()
+// flattenUserAttributes returns the user's attributes keyed by configuration key.
+// Unless raw is set, the "custom:" prefix is removed from attribute names.
+func flattenUserAttributes(apiList []*cognitoidentityprovider.AttributeType, raw bool) map[string]interface{} {
+	tfMap := make(map[string]interface{})
 
-	user, err := FindUserByTwoPartKey(ctx, conn, d.Get("user_pool_id").(string), d.Get("username").(string))
+	for _, apiAttribute := range apiList {
+		if apiAttribute.Name != nil {
+			if raw || UserAttributeKeyMatchesStandardAttribute(*apiAttribute.Name) {
+				tfMap[aws.StringValue(apiAttribute.Name)] = aws.StringValue(apiAttribute.Value)
+			} else {
+				tfMap[userAttributeKey(aws.StringValue(apiAttribute.Name))] = aws.StringValue(apiAttribute.Value)
+			}
+		}
+	}
 
-	if !d.IsNewResource() && tfresource.NotFound(err) {
-		create.LogNotFoundRemoveState(names.CognitoIDP, create.ErrActionReading, ResNameUser, d.Get("username").(string))
-		d.SetId("")
-		return diags
+	return tfMap
+}
+
+// userAttributeUpdateBatches splits the attributes to update into the batches applied by successive AdminUpdateUserAttributes calls.
+// Each attribute in order gets its own batch, in order, and the remaining attributes are applied together last.
+// Without an order all attributes are applied in a single batch.
+func userAttributeUpdateBatches(upd map[string]interface{}, order []string) []map[string]interface{} {
+	if len(upd) == 0 {
+		return nil
 	}
 
-	if err != nil {
-		return create.DiagError(names.CognitoIDP, create.ErrActionReading, ResNameUser, d.Get("username").(string), err)
+	var batches []map[string]interface{}
+	remaining := make(map[string]interface{}, len(upd))
+
+	for k, v := range upd {
+		remaining[k] = v
 	}
 
-	if err := d.Set("attributes", flattenUserAttributes(user.UserAttributes)); err != nil {
-		return sdkdiag.AppendErrorf(diags, "setting user attributes (%s): %s", d.Id(), err)
+	for _, name := range order {
+		for k, v := range remaining {
+			if userAttributeAPIName(k) == userAttributeAPIName(name) {
+				batches = append(batches, map[string]interface{}{k: v})
+				delete(remaining, k)
+			}
+		}
 	}
 
-	if err := d.Set("mfa_setting_list", user.UserMFASettingList); err != nil {
-		return sdkdiag.AppendErrorf(diags, "setting user's mfa settings (%s): %s", d.Id(), err)
+	if len(remaining) > 0 {
+		batches = append(batches, remaining)
 	}
 
-	d.Set("preferred_mfa_setting", user.PreferredMfaSetting)
-	d.Set("status", user.UserStatus)
-	d.Set("enabled", user.Enabled)
-	d.Set("creation_date", user.UserCreateDate.Format(time.RFC3339))
-	d.Set("last_modified_date", user.UserLastModifiedDate.Format(time.RFC3339))
-	d.Set("sub", retrieveUserSub(user.UserAttributes))
+	return batches
+}
 
-	return diags
+// userAliasAttributes are the standard attributes that can be used as sign-in aliases.
+var userAliasAttributes = []string{
+	"email",
+	"phone_number",
+	"preferred_username",
 }
 
-func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	var diags diag.Diagnostics
-	conn := meta.(*conns.AWSClient).CognitoIDPConn()
+// userAliasExistsError adds remediation to an AliasExistsException from updating the user's attributes.
+// Unlike AdminCreateUser, AdminUpdateUserAttributes has no equivalent of force_alias_creation.
+func userAliasExistsError(err error, upd map[string]interface{}) error {
+	if !tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeAliasExistsException) {
+		return err
+	}
 
-	log.Println("[DEBUG] Updating Cognito User")
+	var keys []string
 
-	if d.HasChange("attributes") {
-		old, new := d.GetChange("attributes")
+	for _, k := range userAliasAttributes {
+		if _, ok := upd[k]; ok {
+			keys = append(keys, k)
+		}
+	}
 
-		upd, del := computeUserAttributesUpdate(old, new)
+	return fmt.Errorf("%w; the updated alias attributes (%s) are already used by another user. "+
+		"force_alias_creation only applies when the user is created: remove the alias from the other user, or replace this user with force_alias_creation set to true", err, strings.Join(keys, ", "))
+}
 
-		if len(upd) > 0 {
-			params := &cognitoidentityprovider.AdminUpdateUserAttributesInput{
-				Username:       aws.String(d.Get("username").(string)),
-				UserPoolId:     aws.String(d.Get("user_pool_id").(string)),
-				UserAttributes: expandAttribute(upd),
-			}
+// applyUserAttributesUpdate calls update for each batch of attributes and then remove for the deleted attributes.
+// It returns the attributes that were updated, also when a later batch or the removal fails.
+func applyUserAttributesUpdate(batches []map[string]interface{}, del []*string, update func(map[string]interface{}) error, remove func([]*string) error) (map[string]interface{}, error) {
+	applied := make(map[string]interface{})
 
-			if v, ok := d.GetOk("client_metadata"); ok {
-				metadata := v.(map[string]interface{})
-				params.ClientMetadata = expandUserClientMetadata(metadata)
-			}
+	for _, batch := range batches {
+		if err := update(batch); err != nil {
+			return applied, err
+		}
 
-			_, err := conn.AdminUpdateUserAttributesWithContext(ctx, params)
-			if err != nil {
-				return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): %s", d.Id(), err)
-			}
+		for k, v := range batch {
+			applied[k] = v
 		}
-		if len(del) > 0 {
-			params := &cognitoidentityprovider.AdminDeleteUserAttributesInput{
-				Username:           aws.String(d.Get("username").(string)),
-				UserPoolId:         aws.String(d.Get("user_pool_id").(string)),
-				UserAttributeNames: expandUserAttributesDelete(del),
-			}
-			_, err := conn.AdminDeleteUserAttributesWithContext(ctx, params)
-			if err != nil {
-				return sdkdiag.AppendErrorf(diags, "updating Cognito User Attributes (%s): %s", d.Id(), err)
-			}
+	}
+
+	if len(del) > 0 {
+		if err := remove(del); err != nil {
+			return applied, err
 		}
 	}
 
-	if d.HasChange("enabled") {
-		enabled := d.Get("enabled").(bool)
+	return applied, nil
+}
 
-		if enabled {
-			enableParams := &cognitoidentityprovider.AdminEnableUserInput{
-				Username:   aws.String(d.Get("username").(string)),
-				UserPoolId: aws.String(d.Get("user_pool_id").(string)),
-			}
-			_, err := conn.AdminEnableUserWithContext(ctx, enableParams)
-			if err != nil {
-				return sdkdiag.AppendErrorf(diags, "enabling Cognito User (%s): %s", d.Id(), err)
-			}
-		} else {
-			disableParams := &cognitoidentityprovider.AdminDisableUserInput{
-				Username:   aws.String(d.Get("username").(string)),
-				UserPoolId: aws.String(d.Get("user_pool_id").(string)),
-			}
-			_, err := conn.AdminDisableUserWithContext(ctx, disableParams)
-			if err != nil {
-				return sdkdiag.AppendErrorf(diags, "disabling Cognito User (%s): %s", d.Id(), err)
-			}
-		}
+// validateUserPasswordAgainstPolicy checks the password against the user pool's password policy so that
+// failures point at the unmet requirement rather than Cognito's generic error.
+// The check is skipped if the user pool can't be read, e.g. without cognito-idp:DescribeUserPool permission.
+func validateUserPasswordAgainstPolicy(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPools map[string]*cognitoidentityprovider.UserPoolType, userPoolID, password string) error {
+	userPool, err := findUserPoolByIDCached(ctx, conn, userPools, userPoolID)
+
+	if err != nil {
+		log.Printf("[WARN] Unable to read Cognito User Pool (%s), skipping password policy check: %s", userPoolID, err)
+		return nil
 	}
 
-	if d.HasChange("temporary_password") {
-		password := d.Get("temporary_password").(string)
+	if userPool.Policies == nil {
+		return nil
+	}
 
-		if password != "" {
-			setPasswordParams := &cognitoidentityprovider.AdminSetUserPasswordInput{
-				Username:   aws.String(d.Get("username").(string)),
-				UserPoolId: aws.String(d.Get("user_pool_id").(string)),
-				Password:   aws.String(password),
-				Permanent:  aws.Bool(false),
-			}
+	return userPasswordPolicyError(userPool.Policies.PasswordPolicy, password)
+}
 
-			_, err := conn.AdminSetUserPasswordWithContext(ctx, setPasswordParams)
-			if err != nil {
-				return sdkdiag.AppendErrorf(diags, "changing Cognito User's temporary password (%s): %s", d.Id(), err)
-			}
-		} else {
-			d.Set("temporary_password", nil)
-		}
+// userPasswordSymbols are the special characters Cognito accepts as symbols in passwords.
+const userPasswordSymbols = "^$*.[]{}()?\"!@#%&/\\,><':;|_~`=+- "
+
+// userPasswordPolicyError returns an error describing the first password policy requirement the password doesn't meet.
+func userPasswordPolicyError(policy *cognitoidentityprovider.PasswordPolicyType, password string) error {
+	if policy == nil {
+		return nil
 	}
 
-	if d.HasChange("password") {
-		password := d.Get("password").(string)
+	if v := int(aws.Int64Value(policy.MinimumLength)); utf8.RuneCountInString(password) < v {
+		return fmt.Errorf("must be at least %d characters long to satisfy the user pool password policy", v)
+	}
 
-		if password != "" {
-			setPasswordParams := &cognitoidentityprovider.AdminSetUserPasswordInput{
-				Username:   aws.String(d.Get("username").(string)),
-				UserPoolId: aws.String(d.Get("user_pool_id").(string)),
-				Password:   aws.String(password),
-				Permanent:  aws.Bool(true),
-			}
+	requirements := []struct {
+		required    bool
+		description string
+		matches     func(rune) bool
+	}{
+		{aws.BoolValue(policy.RequireLowercase), "a lowercase letter", unicode.IsLower},
+		{aws.BoolValue(policy.RequireUppercase), "an uppercase letter", unicode.IsUpper},
+		{aws.BoolValue(policy.RequireNumbers), "a number", unicode.IsDigit},
+		{aws.BoolValue(policy.RequireSymbols), "a symbol", func(r rune) bool { return strings.ContainsRune(userPasswordSymbols, r) }},
+	}
 
-			_, err := conn.AdminSetUserPasswordWithContext(ctx, setPasswordParams)
-			if err != nil {
-				return sdkdiag.AppendErrorf(diags, "changing Cognito User's password (%s): %s", d.Id(), err)
-			}
-		} else {
-			d.Set("password", nil)
+	for _, requirement := range requirements {
+		if requirement.required && strings.IndexFunc(password, requirement.matches) == -1 {
+			return fmt.Errorf("must contain %s to satisfy the user pool password policy", requirement.description)
 		}
 	}
 
-	return append(diags, resourceUserRead(ctx, d, meta)...)
+	return nil
 }
 
-func resourceUserDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	var diags diag.Diagnostics
-	conn := meta.(*conns.AWSClient).CognitoIDPConn()
+// userMessageSuppressedWithoutPassword returns whether the welcome message is suppressed for a user created without a password,
+// leaving the user with a temporary password that is never communicated.
+func userMessageSuppressedWithoutPassword(messageAction string, hasPassword, hasTemporaryPassword bool) bool {
+	return messageAction == cognitoidentityprovider.MessageActionTypeSuppress && !hasPassword && !hasTemporaryPassword
+}
 
-	log.Printf("[DEBUG] Deleting Cognito User: %s", d.Id())
-	_, err := conn.AdminDeleteUserWithContext(ctx, &cognitoidentityprovider.AdminDeleteUserInput{
-		Username:   aws.String(d.Get("username").(string)),
-		UserPoolId: aws.String(d.Get("user_pool_id").(string)),
-	})
+// userDefaultDeliveryMediums returns the delivery mediums used when none are configured: EMAIL if the user
+// has an email address and the welcome message isn't suppressed, otherwise none.
+func userDefaultDeliveryMediums(attributes map[string]interface{}, messageAction string) []string {
+	if messageAction == cognitoidentityprovider.MessageActionTypeSuppress {
+		return nil
+	}
 
-	if err != nil {
-		return sdkdiag.AppendErrorf(diags, "deleting Cognito User (%s): %s", d.Id(), err)
+	if v, ok := attributes["email"].(string); !ok || v == "" {
+		return nil
 	}
 
-	return diags
+	return []string{cognitoidentityprovider.DeliveryMediumTypeEmail}
 }
 
-func resourceUserImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	idSplit := strings.Split(d.Id(), "/")
-	if len(idSplit) != 2 {
-		return nil, errors.New("error importing Cognito User. Must specify user_pool_id/username")
-	}
-	userPoolId := idSplit[0]
-	name := idSplit[1]
-	d.Set("user_pool_id", userPoolId)
-	d.Set("username", name)
-	return []*schema.ResourceData{d}, nil
-}
+// userCreateDivergence describes how the created user differs from the configured attributes, enabled flag
+// and, if set, desired status. Values that differ are returned separately from configured attributes that aren't set,
+// which may not have propagated yet. Attributes not configured are ignored.
+func userCreateDivergence(user *cognitoidentityprovider.AdminGetUserOutput, attributes map[string]interface{}, rawNames, enabled bool, desiredStatus string) ([]string, []string) {
+	var divergence, missing []string
 
-func FindUserByTwoPartKey(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string) (*cognitoidentityprovider.AdminGetUserOutput, error) {
-	input := &cognitoidentityprovider.AdminGetUserInput{
-		Username:   aws.String(username),
-		UserPoolId: aws.String(userPoolID),
+	actual := make(map[string]string, len(user.UserAttributes))
+
+	for _, v := range user.UserAttributes {
+		actual[aws.StringValue(v.Name)] = aws.StringValue(v.Value)
 	}
 
-	output, err := conn.AdminGetUserWithContext(ctx, input)
+	for k, v := range attributes {
+		name := userAttributeName(k, rawNames)
 
-	if tfawserr.ErrCodeEquals(err, cognitoidentityprovider.ErrCodeUserNotFoundException, cognitoidentityprovider.ErrCodeResourceNotFoundException) {
-		return nil, &resource.NotFoundError{
-			LastError:   err,
-			LastRequest: input,
+		if got, ok := actual[name]; !ok {
+			missing = append(missing, fmt.Sprintf("attribute %s: expected %q, not set", name, v))
+		} else if got != v.(string) {
+			divergence = append(divergence, fmt.Sprintf("attribute %s: expected %q, got %q", name, v, got))
 		}
 	}
 
-	if err != nil {
-		return nil, err
-	}
+	sort.Strings(divergence)
+	sort.Strings(missing)
 
-	if output == nil {
-		return nil, tfresource.NewEmptyResultError(input)
+	if got := aws.BoolValue(user.Enabled); got != enabled {
+		divergence = append(divergence, fmt.Sprintf("enabled: expected %t, got %t", enabled, got))
 	}
 
-	return output, nil
-}
-
-func expandAttribute(tfMap map[string]interface{}) []*cognitoidentityprovider.AttributeType {
-	if len(tfMap) == 0 {
-		return nil
+	if got := aws.StringValue(user.UserStatus); desiredStatus != "" && got != desiredStatus {
+		divergence = append(divergence, fmt.Sprintf("status: expected %s, got %s", desiredStatus, got))
 	}
 
-	apiList := make([]*cognitoidentityprovider.AttributeType, 0, len(tfMap))
+	return divergence, missing
+}
 
-	for k, v := range tfMap {
-		if !UserAttributeKeyMatchesStandardAttribute(k) && !strings.HasPrefix(k, "custom:") {
-			k = fmt.Sprintf("custom:%v", k)
-		}
-		apiList = append(apiList, &cognitoidentityprovider.AttributeType{
-			Name:  aws.String(k),
-			Value: aws.String(v.(string)),
-		})
+// userAttributeKey returns the configuration key for a Cognito attribute name.
+// It's the inverse of userAttributeAPIName: "custom:" is removed and "dev:" is preserved.
+func userAttributeKey(name string) string {
+	if strings.HasPrefix(name, "dev:") {
+		return "dev:" + strings.TrimPrefix(strings.TrimPrefix(name, "dev:"), "custom:")
 	}
 
-	return apiList
+	return strings.TrimPrefix(name, "custom:")
 }
 
-func expandUserAttributesDelete(input []*string) []*string {
-	result := make([]*string, 0, len(input))
+// userAttributeServerAuthoritativeSuppress returns whether the diff for an attribute should be suppressed
+// when the server is authoritative: changes made outside of Terraform are absorbed unless the configured
+// value differs from the value Terraform last wrote.
+func userAttributeServerAuthoritativeSuppress(name, new string, seeded map[string]interface{}) bool {
+	v, ok := seeded[name]
 
-	for _, v := range input {
-		if !UserAttributeKeyMatchesStandardAttribute(*v) && !strings.HasPrefix(*v, "custom:") {
-			formattedV := fmt.Sprintf("custom:%v", *v)
-			result = append(result, &formattedV)
-		} else {
-			result = append(result, v)
-		}
+	if !ok {
+		// Attributes never written by Terraform are owned by the server.
+		return new == ""
 	}
 
-	return result
+	return v.(string) == new
 }
 
-func flattenUserAttributes(apiList []*cognitoidentityprovider.AttributeType) map[string]interface{} {
-	tfMap := make(map[string]interface{})
+// partitionUserAttributes splits flattened user attributes into standard and custom attributes.
+func partitionUserAttributes(tfMap map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	standard := make(map[string]interface{})
+	custom := make(map[string]interface{})
 
-	for _, apiAttribute := range apiList {
-		if apiAttribute.Name != nil {
-			if UserAttributeKeyMatchesStandardAttribute(*apiAttribute.Name) {
-				tfMap[aws.StringValue(apiAttribute.Name)] = aws.StringValue(apiAttribute.Value)
-			} else {
-				name := strings.TrimPrefix(strings.TrimPrefix(aws.StringValue(apiAttribute.Name), "dev:"), "custom:")
-				tfMap[name] = aws.StringValue(apiAttribute.Value)
-			}
+	for k, v := range tfMap {
+		if UserAttributeKeyMatchesStandardAttribute(k) {
+			standard[k] = v
+		} else {
+			custom[k] = v
 		}
 	}
 
-	return tfMap
+	return standard, custom
 }
 
 // computeUserAttributesUpdate computes which user attributes should be updated and which ones should be deleted.
@@ -500,6 +2949,33 @@ func computeUserAttributesUpdate(old interface{}, new interface{}) (map[string]i
 	return upd, del
 }
 
+// userAttributesPropagated returns whether the user's attributes reflect the updated values and deleted names.
+func userAttributesPropagated(apiList []*cognitoidentityprovider.AttributeType, updated []*cognitoidentityprovider.AttributeType, deleted []*string) bool {
+	current := make(map[string]string, len(apiList))
+
+	for _, v := range apiList {
+		if v == nil {
+			continue
+		}
+
+		current[aws.StringValue(v.Name)] = aws.StringValue(v.Value)
+	}
+
+	for _, v := range updated {
+		if value, ok := current[aws.StringValue(v.Name)]; !ok || value != aws.StringValue(v.Value) {
+			return false
+		}
+	}
+
+	for _, v := range deleted {
+		if _, ok := current[aws.StringValue(v)]; ok {
+			return false
+		}
+	}
+
+	return true
+}
+
 func expandUserDesiredDeliveryMediums(tfSet *schema.Set) []*string {
 	apiList := []*string{}
 
@@ -520,7 +2996,83 @@ func retrieveUserSub(apiList []*cognitoidentityprovider.AttributeType) string {
 	return ""
 }
 
-// For ClientMetadata we only need expand since AWS doesn't store its value
+// reconcileUserAttributes drops the attributes returned by AdminGetUser that ListUsers doesn't return.
+// The sub is always kept.
+func reconcileUserAttributes(apiList, listed []*cognitoidentityprovider.AttributeType) []*cognitoidentityprovider.AttributeType {
+	names := make(map[string]struct{}, len(listed))
+
+	for _, attr := range listed {
+		names[aws.StringValue(attr.Name)] = struct{}{}
+	}
+
+	var reconciled []*cognitoidentityprovider.AttributeType
+
+	for _, attr := range apiList {
+		if _, ok := names[aws.StringValue(attr.Name)]; ok || aws.StringValue(attr.Name) == "sub" {
+			reconciled = append(reconciled, attr)
+		}
+	}
+
+	return reconciled
+}
+
+// userIdentity is an entry of the identities attribute of a federated user.
+type userIdentity struct {
+	Primary      interface{} `json:"primary"`
+	ProviderName string      `json:"providerName"`
+	ProviderType string      `json:"providerType"`
+	UserID       string      `json:"userId"`
+}
+
+// flattenUserIdentities parses the JSON array in the identities attribute.
+// Cognito reports primary either as a boolean or as the string "true" or "false".
+func flattenUserIdentities(v interface{}) ([]interface{}, error) {
+	document, ok := v.(string)
+
+	if !ok || document == "" {
+		return nil, nil
+	}
+
+	var identities []userIdentity
+
+	if err := json.Unmarshal([]byte(document), &identities); err != nil {
+		return nil, err
+	}
+
+	tfList := make([]interface{}, 0, len(identities))
+
+	for _, identity := range identities {
+		var primary bool
+
+		switch v := identity.Primary.(type) {
+		case bool:
+			primary = v
+		case string:
+			primary, _ = strconv.ParseBool(v)
+		}
+
+		tfList = append(tfList, map[string]interface{}{
+			"primary":       primary,
+			"provider_name": identity.ProviderName,
+			"provider_type": identity.ProviderType,
+			"user_id":       identity.UserID,
+		})
+	}
+
+	return tfList, nil
+}
+
+// userIdentityHash returns a stable, opaque identifier for a user.
+// It is derived only from immutable values so it survives username changes.
+func userIdentityHash(userPoolID, sub string) string {
+	hash := sha256.Sum256([]byte(userPoolID + "/" + sub))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// For ClientMetadata we only need expand since AWS doesn't store its value.
+// client_metadata is passed to AdminCreateUser, AdminUpdateUserAttributes and AdminResetUserPassword.
+// AdminSetUserPassword doesn't accept ClientMetadata, so it isn't passed when setting passwords.
 func expandUserClientMetadata(tfMap map[string]interface{}) map[string]*string {
 	apiMap := map[string]*string{}
 	for k, v := range tfMap {
@@ -565,3 +3117,21 @@ func UserAttributeKeyMatchesStandardAttribute(input string) bool {
 	}
 	return false
 }
+
+// isImmutableStandardAttribute returns whether the standard attribute is assigned by Cognito and can't be written.
+func isImmutableStandardAttribute(input string) bool {
+	if !UserAttributeKeyMatchesStandardAttribute(input) {
+		return false
+	}
+
+	var immutableAttributeKeys = []string{
+		"sub",
+	}
+
+	for _, attribute := range immutableAttributeKeys {
+		if input == attribute {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package cognitoidp
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-aws/internal/errs/sdkdiag"
+)
+
+func DataSourceUser() *schema.Resource {
+	return &schema.Resource{
+		ReadWithoutTimeout: dataSourceUserRead,
+
+		Schema: map[string]*schema.Schema{
+			"attributes": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"creation_date": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"last_modified_date": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"mfa_setting_list": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"preferred_mfa_setting": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"sub": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"user_pool_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := userConn(meta)
+
+	userPoolID := d.Get("user_pool_id").(string)
+	username := d.Get("username").(string)
+
+	user, err := findUserByTwoPartKeyCached(ctx, conn, userPoolID, username)
+
+	if err != nil {
+		return sdkdiag.AppendErrorf(diags, "reading Cognito User (%s/%s): %s", userPoolID, username, err)
+	}
+
+	d.SetId(userCreateResourceID(userPoolID, aws.StringValue(user.Username)))
+	d.Set("attributes", flattenUserAttributes(user.UserAttributes, false))
+	d.Set("creation_date", user.UserCreateDate.Format(time.RFC3339))
+	d.Set("enabled", user.Enabled)
+	d.Set("last_modified_date", user.UserLastModifiedDate.Format(time.RFC3339))
+	d.Set("mfa_setting_list", aws.StringValueSlice(user.UserMFASettingList))
+	d.Set("preferred_mfa_setting", user.PreferredMfaSetting)
+	d.Set("status", user.UserStatus)
+	d.Set("sub", retrieveUserSub(user.UserAttributes))
+
+	return diags
+}
@@ -0,0 +1,72 @@
+package cognitoidp_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	sdkacctest "github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-aws/internal/acctest"
+)
+
+func TestAccCognitoIDPUserDataSource_basic(t *testing.T) {
+	ctx := acctest.Context(t)
+	rName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	dataSourceName := "data.aws_cognito_user.test"
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserDataSourceConfig_basic(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair(dataSourceName, "id", resourceName, "id"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "attributes.%", resourceName, "attributes.%"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "attributes.one", resourceName, "attributes.one"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "creation_date", resourceName, "creation_date"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "enabled", resourceName, "enabled"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "last_modified_date", resourceName, "last_modified_date"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "mfa_setting_list.#", resourceName, "mfa_setting_list.#"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "preferred_mfa_setting", resourceName, "preferred_mfa_setting"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "status", resourceName, "status"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "sub", resourceName, "sub"),
+				),
+			},
+		},
+	})
+}
+
+func testAccUserDataSourceConfig_basic(rName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  schema {
+    name                     = "one"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[1]q
+
+  attributes = {
+    one = "1"
+  }
+}
+
+data "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user.test.user_pool_id
+  username     = aws_cognito_user.test.username
+}
+`, rName)
+}
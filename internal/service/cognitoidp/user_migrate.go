@@ -0,0 +1,135 @@
+package cognitoidp
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceUserV0 returns the version 0 schema, the schema of the user resource before its ID format changed.
+// Attributes added since are not in version 0 states. Only the types of its attributes are used, to decode version 0 states.
+func resourceUserV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"attributes": {
+				Type: schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					if k == "attributes.sub" || k == "attributes.%" {
+						return true
+					}
+
+					return false
+				},
+				Optional: true,
+			},
+			"client_metadata": {
+				Type:     schema.TypeMap,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			"creation_date": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"desired_delivery_mediums": {
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(cognitoidentityprovider.DeliveryMediumType_Values(), false),
+				},
+				Optional: true,
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"force_alias_creation": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"last_modified_date": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"message_action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(cognitoidentityprovider.MessageActionType_Values(), false),
+			},
+			"mfa_setting_list": {
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			"preferred_mfa_setting": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"user_pool_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"username": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringLenBetween(1, 128),
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"sub": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"password": {
+				Type:          schema.TypeString,
+				Sensitive:     true,
+				Optional:      true,
+				ValidateFunc:  validation.StringLenBetween(6, 256),
+				ConflictsWith: []string{"temporary_password"},
+			},
+			"temporary_password": {
+				Type:          schema.TypeString,
+				Sensitive:     true,
+				Optional:      true,
+				ValidateFunc:  validation.StringLenBetween(6, 256),
+				ConflictsWith: []string{"password"},
+			},
+			"validation_data": {
+				Type: schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+			},
+		},
+	}
+}
+
+// UserStateUpgradeV0 rewrites the user_pool_id/username ID with the username URL-encoded.
+// Attributes added since version 0 are left unset, and take their defaults or are populated by the next read.
+func UserStateUpgradeV0(_ context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		rawState = map[string]interface{}{}
+	}
+
+	userPoolID, _ := rawState["user_pool_id"].(string)
+	username, _ := rawState["username"].(string)
+
+	if userPoolID != "" && username != "" {
+		rawState["id"] = userCreateResourceID(userPoolID, username)
+	}
+
+	return rawState, nil
+}
@@ -0,0 +1,86 @@
+package cognitoidp_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-provider-aws/internal/acctest"
+	tfcognitoidp "github.com/hashicorp/terraform-provider-aws/internal/service/cognitoidp"
+)
+
+func TestUserStateUpgradeV0(t *testing.T) {
+	ctx := acctest.Context(t)
+	t.Parallel()
+
+	testCases := []struct {
+		TestName string
+		RawState map[string]interface{}
+		Expected map[string]interface{}
+	}{
+		{
+			TestName: "plain username",
+			RawState: map[string]interface{}{
+				"id":           "us-east-1_vG78M4goG/user",
+				"user_pool_id": "us-east-1_vG78M4goG",
+				"username":     "user",
+			},
+			Expected: map[string]interface{}{
+				"id":           "us-east-1_vG78M4goG/user",
+				"user_pool_id": "us-east-1_vG78M4goG",
+				"username":     "user",
+			},
+		},
+		{
+			TestName: "slash in username",
+			RawState: map[string]interface{}{
+				"id":           "us-east-1_vG78M4goG/idp/user",
+				"user_pool_id": "us-east-1_vG78M4goG",
+				"username":     "idp/user",
+			},
+			Expected: map[string]interface{}{
+				"id":           "us-east-1_vG78M4goG/idp%2Fuser",
+				"user_pool_id": "us-east-1_vG78M4goG",
+				"username":     "idp/user",
+			},
+		},
+		{
+			TestName: "colon in username",
+			RawState: map[string]interface{}{
+				"id":           "us-east-1_vG78M4goG/idp:user",
+				"user_pool_id": "us-east-1_vG78M4goG",
+				"username":     "idp:user",
+			},
+			Expected: map[string]interface{}{
+				"id":           "us-east-1_vG78M4goG/idp:user",
+				"user_pool_id": "us-east-1_vG78M4goG",
+				"username":     "idp:user",
+			},
+		},
+		{
+			TestName: "missing username",
+			RawState: map[string]interface{}{
+				"id": "us-east-1_vG78M4goG/user",
+			},
+			Expected: map[string]interface{}{
+				"id": "us-east-1_vG78M4goG/user",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := tfcognitoidp.UserStateUpgradeV0(ctx, testCase.RawState, nil)
+
+			if err != nil {
+				t.Fatalf("error migrating state: %s", err)
+			}
+
+			if !reflect.DeepEqual(testCase.Expected, actual) {
+				t.Fatalf("\n\nexpected:\n\n%#v\n\ngot:\n\n%#v\n\n", testCase.Expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package cognitoidp
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-aws/internal/conns"
+	"github.com/hashicorp/terraform-provider-aws/internal/errs/sdkdiag"
+)
+
+func DataSourceUserPoolUserCount() *schema.Resource {
+	return &schema.Resource{
+		ReadWithoutTimeout: dataSourceUserPoolUserCountRead,
+
+		Schema: map[string]*schema.Schema{
+			"count_by_status": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"estimated_number_of_users": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"user_counts_by_status": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+			"user_pool_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+func dataSourceUserPoolUserCountRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := meta.(*conns.AWSClient).CognitoIDPConn()
+
+	userPoolID := d.Get("user_pool_id").(string)
+
+	userPool, err := FindUserPoolByID(ctx, conn, userPoolID)
+
+	if err != nil {
+		return sdkdiag.AppendErrorf(diags, "reading Cognito User Pool (%s): %s", userPoolID, err)
+	}
+
+	d.SetId(userPoolID)
+	d.Set("estimated_number_of_users", userPool.EstimatedNumberOfUsers)
+
+	// Counting users by status enumerates every user in the pool, so it's opt-in.
+	if d.Get("count_by_status").(bool) {
+		counts, err := FindUserStatusCounts(ctx, conn, userPoolID)
+
+		if err != nil {
+			return sdkdiag.AppendErrorf(diags, "listing Cognito User Pool (%s) users: %s", userPoolID, err)
+		}
+
+		tfMap := make(map[string]interface{}, len(counts))
+		for k, v := range counts {
+			tfMap[k] = v
+		}
+
+		d.Set("user_counts_by_status", tfMap)
+	} else {
+		d.Set("user_counts_by_status", nil)
+	}
+
+	return diags
+}
+
+func userStatusCounts(users []*cognitoidentityprovider.UserType) map[string]int {
+	counts := make(map[string]int)
+
+	for _, user := range users {
+		if user == nil {
+			continue
+		}
+
+		counts[aws.StringValue(user.UserStatus)]++
+	}
+
+	return counts
+}
@@ -0,0 +1,90 @@
+package cognitoidp_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	sdkacctest "github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-aws/internal/acctest"
+	tfcognitoidp "github.com/hashicorp/terraform-provider-aws/internal/service/cognitoidp"
+)
+
+func TestAccCognitoIDPUserPoolUserCountDataSource_basic(t *testing.T) {
+	ctx := acctest.Context(t)
+	rName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	dataSourceName := "data.aws_cognito_user_pool_user_count.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserPoolUserCountDataSourceConfig_basic(rName, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet(dataSourceName, "estimated_number_of_users"),
+					resource.TestCheckResourceAttr(dataSourceName, "user_counts_by_status.%", "0"),
+				),
+			},
+			{
+				Config: testAccUserPoolUserCountDataSourceConfig_basic(rName, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet(dataSourceName, "estimated_number_of_users"),
+					resource.TestCheckResourceAttr(dataSourceName, "user_counts_by_status.%", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "user_counts_by_status.CONFIRMED", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "user_counts_by_status.FORCE_CHANGE_PASSWORD", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestUserStatusCounts(t *testing.T) {
+	t.Parallel()
+
+	users := []*cognitoidentityprovider.UserType{
+		{UserStatus: aws.String(cognitoidentityprovider.UserStatusTypeConfirmed)},
+		nil,
+		{UserStatus: aws.String(cognitoidentityprovider.UserStatusTypeForceChangePassword)},
+		{UserStatus: aws.String(cognitoidentityprovider.UserStatusTypeConfirmed)},
+	}
+
+	want := map[string]int{
+		cognitoidentityprovider.UserStatusTypeConfirmed:           2,
+		cognitoidentityprovider.UserStatusTypeForceChangePassword: 1,
+	}
+
+	if got := tfcognitoidp.UserStatusCounts(users); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+}
+
+func testAccUserPoolUserCountDataSourceConfig_basic(rName string, countByStatus bool) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+
+resource "aws_cognito_user" "confirmed" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = "%[1]s-confirmed"
+  password     = "Password1!"
+}
+
+resource "aws_cognito_user" "force_change_password" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = "%[1]s-force-change-password"
+}
+
+data "aws_cognito_user_pool_user_count" "test" {
+  user_pool_id    = aws_cognito_user_pool.test.id
+  count_by_status = %[2]t
+
+  depends_on = [aws_cognito_user.confirmed, aws_cognito_user.force_change_password]
+}
+`, rName, countByStatus)
+}
@@ -4,12 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/hashicorp/go-cty/cty"
 	sdkacctest "github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/hashicorp/terraform-provider-aws/internal/acctest"
 	"github.com/hashicorp/terraform-provider-aws/internal/conns"
@@ -36,10 +47,19 @@ func TestAccCognitoIDPUser_basic(t *testing.T) {
 					resource.TestCheckResourceAttrSet(resourceName, "creation_date"),
 					resource.TestCheckResourceAttrSet(resourceName, "last_modified_date"),
 					resource.TestCheckResourceAttrSet(resourceName, "sub"),
+					resource.TestCheckResourceAttrSet(resourceName, "identity_hash"),
 					resource.TestCheckResourceAttr(resourceName, "preferred_mfa_setting", ""),
+					resource.TestCheckNoResourceAttr(resourceName, "mfa_enrolled_at"),
+					resource.TestCheckResourceAttr(resourceName, "mfa_fallback_order.#", "0"),
 					resource.TestCheckResourceAttr(resourceName, "mfa_setting_list.#", "0"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "user_attributes.*", map[string]string{
+						"name": "sub",
+					}),
 					resource.TestCheckResourceAttr(resourceName, "enabled", "true"),
 					resource.TestCheckResourceAttr(resourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+					resource.TestCheckResourceAttr(resourceName, "username_resolved", rUserName),
+					resource.TestCheckResourceAttrPair(resourceName, "user_reference", resourceName, "id"),
+					resource.TestCheckNoResourceAttr(resourceName, "seeded_attributes.%"),
 				),
 			},
 			{
@@ -59,11 +79,19 @@ func TestAccCognitoIDPUser_basic(t *testing.T) {
 	})
 }
 
-func TestAccCognitoIDPUser_disappears(t *testing.T) {
+func TestAccCognitoIDPUser_importColonSeparator(t *testing.T) {
 	ctx := acctest.Context(t)
 	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
-	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix) + "/federated"
 	resourceName := "aws_cognito_user.test"
+	importStateVerifyIgnore := []string{
+		"temporary_password",
+		"password",
+		"client_metadata",
+		"validation_data",
+		"desired_delivery_mediums",
+		"message_action",
+	}
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:                 func() { acctest.PreCheck(t) },
@@ -75,23 +103,59 @@ func TestAccCognitoIDPUser_disappears(t *testing.T) {
 				Config: testAccUserConfig_basic(rUserPoolName, rUserName),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckUserExists(ctx, resourceName),
-					acctest.CheckResourceDisappears(ctx, acctest.Provider, tfcognitoidp.ResourceUser(), resourceName),
+					resource.TestCheckResourceAttr(resourceName, "username", rUserName),
+					resource.TestMatchResourceAttr(resourceName, "id", regexp.MustCompile(`^[^/]+/[^/]+%2Ffederated$`)),
 				),
-				ExpectNonEmptyPlan: true,
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: importStateVerifyIgnore,
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateIdFunc:       testAccUserImportStateIdFunc(resourceName, ":"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: importStateVerifyIgnore,
 			},
 		},
 	})
 }
 
-func TestAccCognitoIDPUser_temporaryPassword(t *testing.T) {
+func TestAccCognitoIDPUser_verifyCreate(t *testing.T) {
+	ctx := acctest.Context(t)
+	rName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccUserConfig_verifyCreate(rName, "false"),
+				ExpectError: regexp.MustCompile(`attribute email_verified: expected "false", got "true"`),
+			},
+			{
+				Config: testAccUserConfig_verifyCreate(rName, "true"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attributes.email_verified", "true"),
+					resource.TestCheckResourceAttr(resourceName, "verify_create", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_usernameCaseInsensitive(t *testing.T) {
 	ctx := acctest.Context(t)
 	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
 	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
-	rClientName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
-	rUserPassword := sdkacctest.RandString(16)
-	rUserPasswordUpdated := sdkacctest.RandString(16)
-	userResourceName := "aws_cognito_user.test"
-	clientResourceName := "aws_cognito_user_pool_client.test"
+	resourceName := "aws_cognito_user.test"
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:                 func() { acctest.PreCheck(t) },
@@ -100,55 +164,60 @@ func TestAccCognitoIDPUser_temporaryPassword(t *testing.T) {
 		CheckDestroy:             testAccCheckUserDestroy(ctx),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccUserConfig_temporaryPassword(rUserPoolName, rClientName, rUserName, rUserPassword),
+				Config: testAccUserConfig_usernameCaseInsensitive(rUserPoolName, rUserName),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckUserExists(ctx, userResourceName),
-					testAccUserTemporaryPassword(ctx, userResourceName, clientResourceName),
-					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "username", rUserName),
 				),
 			},
 			{
-				ResourceName:      userResourceName,
-				ImportState:       true,
-				ImportStateVerify: true,
-				ImportStateVerifyIgnore: []string{
-					"temporary_password",
-					"password",
-					"client_metadata",
-					"validation_data",
-					"desired_delivery_mediums",
-					"message_action",
-				},
+				Config:   testAccUserConfig_usernameCaseInsensitive(rUserPoolName, strings.ToUpper(rUserName)),
+				PlanOnly: true,
 			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_attributeApplyOrder(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
 			{
-				Config: testAccUserConfig_temporaryPassword(rUserPoolName, rClientName, rUserName, rUserPasswordUpdated),
+				Config: testAccUserConfig_attributeApplyOrder(rUserPoolName, rUserName, "test1@example.com", "false"),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckUserExists(ctx, userResourceName),
-					testAccUserTemporaryPassword(ctx, userResourceName, clientResourceName),
-					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attribute_apply_order.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "attribute_apply_order.0", "email"),
+					resource.TestCheckResourceAttr(resourceName, "attribute_apply_order.1", "email_verified"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.email", "test1@example.com"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.email_verified", "false"),
 				),
 			},
 			{
-				Config: testAccUserConfig_noPassword(rUserPoolName, rClientName, rUserName),
+				Config: testAccUserConfig_attributeApplyOrder(rUserPoolName, rUserName, "test2@example.com", "true"),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckUserExists(ctx, userResourceName),
-					resource.TestCheckResourceAttr(userResourceName, "temporary_password", ""),
-					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attributes.email", "test2@example.com"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.email_verified", "true"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccCognitoIDPUser_password(t *testing.T) {
+func TestAccCognitoIDPUser_verifiedAttributes(t *testing.T) {
 	ctx := acctest.Context(t)
 	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
 	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
-	rClientName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
-	rUserPassword := sdkacctest.RandString(16)
-	rUserPasswordUpdated := sdkacctest.RandString(16)
-	userResourceName := "aws_cognito_user.test"
-	clientResourceName := "aws_cognito_user_pool_client.test"
+	resourceName := "aws_cognito_user.test"
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:                 func() { acctest.PreCheck(t) },
@@ -157,47 +226,59 @@ func TestAccCognitoIDPUser_password(t *testing.T) {
 		CheckDestroy:             testAccCheckUserDestroy(ctx),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccUserConfig_password(rUserPoolName, rClientName, rUserName, rUserPassword),
+				Config: testAccUserConfig_verifiedAttributes(rUserPoolName, rUserName, true, false),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckUserExists(ctx, userResourceName),
-					testAccUserPassword(ctx, userResourceName, clientResourceName),
-					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "email_verified", "true"),
+					resource.TestCheckResourceAttr(resourceName, "phone_number_verified", "false"),
 				),
 			},
 			{
-				ResourceName:      userResourceName,
-				ImportState:       true,
-				ImportStateVerify: true,
-				ImportStateVerifyIgnore: []string{
-					"temporary_password",
-					"password",
-					"client_metadata",
-					"validation_data",
-					"desired_delivery_mediums",
-					"message_action",
-				},
+				Config: testAccUserConfig_verifiedAttributes(rUserPoolName, rUserName, false, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "email_verified", "false"),
+					resource.TestCheckResourceAttr(resourceName, "phone_number_verified", "true"),
+				),
 			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_consistentRead(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
 			{
-				Config: testAccUserConfig_password(rUserPoolName, rClientName, rUserName, rUserPasswordUpdated),
+				Config: testAccUserConfig_consistentRead(rUserPoolName, rUserName, true),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckUserExists(ctx, userResourceName),
-					testAccUserPassword(ctx, userResourceName, clientResourceName),
-					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "consistent_read", "true"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.%", "2"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.name", "test"),
 				),
 			},
 			{
-				Config: testAccUserConfig_noPassword(rUserPoolName, rClientName, rUserName),
+				Config: testAccUserConfig_consistentRead(rUserPoolName, rUserName, false),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckUserExists(ctx, userResourceName),
-					resource.TestCheckResourceAttr(userResourceName, "password", ""),
-					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attributes.%", "1"),
+					resource.TestCheckNoResourceAttr(resourceName, "attributes.name"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccCognitoIDPUser_attributes(t *testing.T) {
+func TestAccCognitoIDPUser_tags(t *testing.T) {
 	ctx := acctest.Context(t)
 	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
 	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
@@ -210,13 +291,12 @@ func TestAccCognitoIDPUser_attributes(t *testing.T) {
 		CheckDestroy:             testAccCheckUserDestroy(ctx),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccUserConfig_attributes(rUserPoolName, rUserName),
+				Config: testAccUserConfig_tags(rUserPoolName, rUserName, "test"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckUserExists(ctx, resourceName),
-					resource.TestCheckResourceAttr(resourceName, "attributes.%", "4"),
-					resource.TestCheckResourceAttr(resourceName, "attributes.one", "1"),
-					resource.TestCheckResourceAttr(resourceName, "attributes.two", "2"),
-					resource.TestCheckResourceAttr(resourceName, "attributes.three", "3"),
+					resource.TestCheckResourceAttr(resourceName, "tags.%", "1"),
+					resource.TestCheckResourceAttr(resourceName, "tags.env", "test"),
+					resource.TestCheckNoResourceAttr(resourceName, "attributes.tag_env"),
 				),
 			},
 			{
@@ -233,20 +313,18 @@ func TestAccCognitoIDPUser_attributes(t *testing.T) {
 				},
 			},
 			{
-				Config: testAccUserConfig_attributesUpdated(rUserPoolName, rUserName),
+				Config: testAccUserConfig_tags(rUserPoolName, rUserName, "prod"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckUserExists(ctx, resourceName),
-					resource.TestCheckResourceAttr(resourceName, "attributes.%", "4"),
-					resource.TestCheckResourceAttr(resourceName, "attributes.two", "2"),
-					resource.TestCheckResourceAttr(resourceName, "attributes.three", "three"),
-					resource.TestCheckResourceAttr(resourceName, "attributes.four", "4"),
+					resource.TestCheckResourceAttr(resourceName, "tags.%", "1"),
+					resource.TestCheckResourceAttr(resourceName, "tags.env", "prod"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccCognitoIDPUser_enabled(t *testing.T) {
+func TestAccCognitoIDPUser_confirm(t *testing.T) {
 	ctx := acctest.Context(t)
 	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
 	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
@@ -259,165 +337,4394 @@ func TestAccCognitoIDPUser_enabled(t *testing.T) {
 		CheckDestroy:             testAccCheckUserDestroy(ctx),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccUserConfig_enable(rUserPoolName, rUserName, false),
+				Config: testAccUserConfig_confirm(rUserPoolName, rUserName),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckUserExists(ctx, resourceName),
-					resource.TestCheckResourceAttr(resourceName, "enabled", "false"),
+					resource.TestCheckResourceAttr(resourceName, "confirm", "true"),
+					resource.TestCheckResourceAttr(resourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
 				),
 			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_attributeBlocks(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
 			{
-				ResourceName:      resourceName,
+				Config: testAccUserConfig_attributeBlocks(rUserPoolName, rUserName, "test1@example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attribute.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "attribute.*", map[string]string{
+						"name":  "email",
+						"value": "test1@example.com",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "attribute.*", map[string]string{
+						"name":  "name",
+						"value": "test",
+					}),
+					resource.TestCheckResourceAttr(resourceName, "attributes.%", "0"),
+				),
+			},
+			{
+				Config: testAccUserConfig_attributeBlocks(rUserPoolName, rUserName, "test2@example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attribute.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "attribute.*", map[string]string{
+						"name":  "email",
+						"value": "test2@example.com",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_passwordPermanent(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rClientName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserPassword := sdkacctest.RandString(16)
+	userResourceName := "aws_cognito_user.test"
+	clientResourceName := "aws_cognito_user_pool_client.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_passwordPermanent(rUserPoolName, rClientName, rUserName, rUserPassword, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, userResourceName),
+					testAccUserTemporaryPassword(ctx, userResourceName, clientResourceName),
+					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+					resource.TestCheckResourceAttr(userResourceName, "password_reset_required", "true"),
+				),
+			},
+			{
+				ResourceName:      userResourceName,
 				ImportState:       true,
 				ImportStateVerify: true,
 				ImportStateVerifyIgnore: []string{
-					"temporary_password",
 					"password",
-					"client_metadata",
-					"validation_data",
-					"desired_delivery_mediums",
-					"message_action",
+					"password_permanent",
 				},
 			},
 			{
-				Config: testAccUserConfig_enable(rUserPoolName, rUserName, true),
+				Config: testAccUserConfig_passwordPermanent(rUserPoolName, rClientName, rUserName, rUserPassword, true),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckUserExists(ctx, resourceName),
-					resource.TestCheckResourceAttr(resourceName, "enabled", "true"),
+					testAccCheckUserExists(ctx, userResourceName),
+					testAccUserPassword(ctx, userResourceName, clientResourceName),
+					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
+					resource.TestCheckResourceAttr(userResourceName, "password_reset_required", "false"),
 				),
 			},
 		},
 	})
 }
 
-func testAccCheckUserExists(ctx context.Context, n string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		rs, ok := s.RootModule().Resources[n]
-		if !ok {
-			return fmt.Errorf("Not found: %s", n)
-		}
-
-		if rs.Primary.ID == "" {
-			return fmt.Errorf("No Cognito User ID is set")
-		}
-
-		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
-
-		_, err := tfcognitoidp.FindUserByTwoPartKey(ctx, conn, rs.Primary.Attributes["user_pool_id"], rs.Primary.Attributes["username"])
+func TestAccCognitoIDPUser_temporaryPasswordPolicy(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
 
-		return err
-	}
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccUserConfig_temporaryPasswordPolicy(rUserPoolName, rUserName, "password1!"),
+				ExpectError: regexp.MustCompile(`temporary_password must contain an uppercase letter`),
+			},
+		},
+	})
 }
 
-func testAccCheckUserDestroy(ctx context.Context) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
-
-		for _, rs := range s.RootModule().Resources {
-			if rs.Type != "aws_cognito_user" {
-				continue
-			}
-
-			_, err := tfcognitoidp.FindUserByTwoPartKey(ctx, conn, rs.Primary.Attributes["user_pool_id"], rs.Primary.Attributes["username"])
-
-			if tfresource.NotFound(err) {
-				continue
-			}
-
-			if err != nil {
-				return err
-			}
+func TestAccCognitoIDPUser_globalSignOut(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_globalSignOut(rUserPoolName, rUserName, true, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "global_sign_out", "1"),
+				),
+			},
+			{
+				// The trigger is kept in state as configured, so it only plans again when it changes.
+				Config: testAccUserConfig_globalSignOut(rUserPoolName, rUserName, true, "2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "global_sign_out", "2"),
+				),
+			},
+			{
+				Config:      testAccUserConfig_globalSignOut(rUserPoolName, rUserName, false, "3"),
+				ExpectError: regexp.MustCompile(`user is disabled`),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_resendExisting(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_resendExisting(rUserPoolName, rUserName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserCreateOutOfBand(ctx, "aws_cognito_user_pool.test", rUserName),
+				),
+			},
+			{
+				Config: testAccUserConfig_resendExisting(rUserPoolName, rUserName, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "username", rUserName),
+					resource.TestCheckResourceAttr(resourceName, "message_action", cognitoidentityprovider.MessageActionTypeResend),
+					resource.TestCheckResourceAttr(resourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_disappears(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_basic(rUserPoolName, rUserName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					acctest.CheckResourceDisappears(ctx, acctest.Provider, tfcognitoidp.ResourceUser(), resourceName),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_disappears_userPool(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+	userPoolResourceName := "aws_cognito_user_pool.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_basic(rUserPoolName, rUserName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					acctest.CheckResourceDisappears(ctx, acctest.Provider, tfcognitoidp.ResourceUserPool(), userPoolResourceName),
+					acctest.CheckResourceDisappears(ctx, acctest.Provider, tfcognitoidp.ResourceUser(), resourceName),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_temporaryPassword(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rClientName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserPassword := sdkacctest.RandString(16)
+	rUserPasswordUpdated := sdkacctest.RandString(16)
+	userResourceName := "aws_cognito_user.test"
+	clientResourceName := "aws_cognito_user_pool_client.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_temporaryPassword(rUserPoolName, rClientName, rUserName, rUserPassword),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, userResourceName),
+					testAccUserTemporaryPassword(ctx, userResourceName, clientResourceName),
+					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+				),
+			},
+			{
+				ResourceName:      userResourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"temporary_password",
+					"password",
+					"client_metadata",
+					"validation_data",
+					"desired_delivery_mediums",
+					"message_action",
+				},
+			},
+			{
+				Config: testAccUserConfig_temporaryPassword(rUserPoolName, rClientName, rUserName, rUserPasswordUpdated),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, userResourceName),
+					testAccUserTemporaryPassword(ctx, userResourceName, clientResourceName),
+					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+				),
+			},
+			{
+				Config: testAccUserConfig_noPassword(rUserPoolName, rClientName, rUserName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, userResourceName),
+					resource.TestCheckResourceAttr(userResourceName, "temporary_password", ""),
+					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_password(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rClientName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserPassword := sdkacctest.RandString(16)
+	rUserPasswordUpdated := sdkacctest.RandString(16)
+	userResourceName := "aws_cognito_user.test"
+	clientResourceName := "aws_cognito_user_pool_client.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_password(rUserPoolName, rClientName, rUserName, rUserPassword),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, userResourceName),
+					testAccUserPassword(ctx, userResourceName, clientResourceName),
+					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
+				),
+			},
+			{
+				ResourceName:      userResourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"temporary_password",
+					"password",
+					"client_metadata",
+					"validation_data",
+					"desired_delivery_mediums",
+					"message_action",
+				},
+			},
+			{
+				Config: testAccUserConfig_password(rUserPoolName, rClientName, rUserName, rUserPasswordUpdated),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, userResourceName),
+					testAccUserPassword(ctx, userResourceName, clientResourceName),
+					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
+				),
+			},
+			{
+				Config: testAccUserConfig_noPassword(rUserPoolName, rClientName, rUserName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, userResourceName),
+					resource.TestCheckResourceAttr(userResourceName, "password", ""),
+					resource.TestCheckResourceAttr(userResourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_attributes(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_attributes(rUserPoolName, rUserName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attributes.%", "4"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.one", "1"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.two", "2"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.three", "3"),
+					resource.TestCheckResourceAttr(resourceName, "custom_attributes.%", "3"),
+					resource.TestCheckResourceAttr(resourceName, "custom_attributes.one", "1"),
+					resource.TestCheckResourceAttr(resourceName, "standard_attributes.%", "1"),
+					resource.TestCheckResourceAttrSet(resourceName, "standard_attributes.sub"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"temporary_password",
+					"password",
+					"client_metadata",
+					"validation_data",
+					"desired_delivery_mediums",
+					"message_action",
+				},
+			},
+			{
+				Config: testAccUserConfig_attributesUpdated(rUserPoolName, rUserName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attributes.%", "4"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.two", "2"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.three", "three"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.four", "4"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_enabled(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_enable(rUserPoolName, rUserName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					testAccCheckUserEnabled(ctx, resourceName, false),
+					resource.TestCheckResourceAttr(resourceName, "enabled", "false"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"temporary_password",
+					"password",
+					"client_metadata",
+					"validation_data",
+					"desired_delivery_mediums",
+					"message_action",
+				},
+			},
+			{
+				Config: testAccUserConfig_enable(rUserPoolName, rUserName, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "enabled", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_rawAttributeNames(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_rawAttributeNames(rUserPoolName, rUserName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					testAccCheckUserAttribute(ctx, resourceName, "custom:one", "1"),
+					resource.TestCheckResourceAttr(resourceName, "raw_attribute_names", "true"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.custom:one", "1"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.email", "test@example.com"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_attributesDocument(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_attributesDocument(rUserPoolName, rUserName, `{"one":"1","two":"2"}`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					testAccCheckUserAttribute(ctx, resourceName, "custom:one", "1"),
+					testAccCheckUserAttribute(ctx, resourceName, "custom:two", "two"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.two", "two"),
+					resource.TestCheckNoResourceAttr(resourceName, "attributes.one"),
+				),
+			},
+			{
+				Config: testAccUserConfig_attributesDocument(rUserPoolName, rUserName, `{"one":"one","two":"2"}`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					testAccCheckUserAttribute(ctx, resourceName, "custom:one", "one"),
+					testAccCheckUserAttribute(ctx, resourceName, "custom:two", "two"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_attributesJSON(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_attributesJSON(rUserPoolName, rUserName, `{"one":"1"}`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					testAccCheckUserAttribute(ctx, resourceName, "custom:one", "1"),
+					testAccCheckUserAttribute(ctx, resourceName, "custom:two", "two"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.two", "two"),
+					resource.TestCheckNoResourceAttr(resourceName, "attributes.one"),
+				),
+			},
+			{
+				Config:      testAccUserConfig_attributesJSON(rUserPoolName, rUserName, `{"one":"1","two":"2"}`),
+				ExpectError: regexp.MustCompile(`attributes_json keys also set in attributes: two`),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_waitForAttributePropagation(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_waitForAttributePropagation(rUserPoolName, rUserName, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attributes.one", "1"),
+				),
+			},
+			{
+				Config: testAccUserConfig_waitForAttributePropagation(rUserPoolName, rUserName, "one"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attributes.one", "one"),
+				),
+			},
+			{
+				Config:   testAccUserConfig_waitForAttributePropagation(rUserPoolName, rUserName, "one"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_attributeMergeStrategyServerAuthoritative(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_attributeMergeStrategy(rUserPoolName, rUserName, "server_authoritative", "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attributes.one", "1"),
+					resource.TestCheckResourceAttr(resourceName, "seeded_attributes.one", "1"),
+					testAccUpdateUserAttribute(ctx, resourceName, "custom:one", "external"),
+				),
+			},
+			{
+				Config: testAccUserConfig_attributeMergeStrategy(rUserPoolName, rUserName, "server_authoritative", "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserAttribute(ctx, resourceName, "custom:one", "external"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.one", "external"),
+				),
+			},
+			{
+				Config: testAccUserConfig_attributeMergeStrategy(rUserPoolName, rUserName, "server_authoritative", "2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserAttribute(ctx, resourceName, "custom:one", "2"),
+					resource.TestCheckResourceAttr(resourceName, "seeded_attributes.one", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_allowedAttributeKeys(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccUserConfig_allowedAttributeKeys(rUserPoolName, rUserName, `["one"]`),
+				ExpectError: regexp.MustCompile(`attributes not in allowed_attribute_keys: two`),
+			},
+			{
+				Config: testAccUserConfig_allowedAttributeKeys(rUserPoolName, rUserName, `["one", "custom:two"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "allowed_attribute_keys.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_desiredStatus(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rEmail := acctest.RandomEmailAddress(acctest.RandomDomainName())
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_desiredStatus(rUserPoolName, rUserName, rEmail, "password", "Password1!", cognitoidentityprovider.UserStatusTypeConfirmed),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "desired_status", cognitoidentityprovider.UserStatusTypeConfirmed),
+					resource.TestCheckResourceAttr(resourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
+				),
+			},
+			{
+				Config: testAccUserConfig_desiredStatus(rUserPoolName, rUserName, rEmail, "temporary_password", "Password2!", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "status", cognitoidentityprovider.UserStatusTypeForceChangePassword),
+				),
+			},
+			{
+				Config:      testAccUserConfig_desiredStatus(rUserPoolName, rUserName, rEmail, "temporary_password", "Password2!", cognitoidentityprovider.UserStatusTypeResetRequired),
+				ExpectError: regexp.MustCompile(`user password can only be reset from status CONFIRMED`),
+			},
+			{
+				Config: testAccUserConfig_desiredStatus(rUserPoolName, rUserName, rEmail, "password", "Password3!", cognitoidentityprovider.UserStatusTypeResetRequired),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "status", cognitoidentityprovider.UserStatusTypeResetRequired),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_forcePasswordReset(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rEmail := acctest.RandomEmailAddress(acctest.RandomDomainName())
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_forcePasswordReset(rUserPoolName, rUserName, rEmail, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "force_password_reset", "false"),
+					resource.TestCheckResourceAttr(resourceName, "status", cognitoidentityprovider.UserStatusTypeConfirmed),
+				),
+			},
+			{
+				Config: testAccUserConfig_forcePasswordReset(rUserPoolName, rUserName, rEmail, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "force_password_reset", "true"),
+					resource.TestCheckResourceAttr(resourceName, "status", cognitoidentityprovider.UserStatusTypeResetRequired),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_attributesNotInSchema(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccUserConfig_attributesNotInSchema(rUserPoolName, rUserName),
+				ExpectError: regexp.MustCompile(`attributes not declared in Cognito User Pool \(.+\) schema: custom:tow`),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_developerOnlyAttribute(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_developerOnlyAttribute(rUserPoolName, rUserName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "attributes.%", "1"),
+					resource.TestCheckResourceAttr(resourceName, "attributes.dev:secret", "1"),
+				),
+			},
+			{
+				Config:   testAccUserConfig_developerOnlyAttribute(rUserPoolName, rUserName),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_defaultGroups(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rGroupName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_defaultGroups(rUserPoolName, rUserName, rGroupName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					testAccCheckUserInGroup(ctx, resourceName, rGroupName+"-1"),
+					testAccCheckUserInGroup(ctx, resourceName, rGroupName+"-2"),
+					resource.TestCheckResourceAttr(resourceName, "default_groups.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_groups(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rGroupName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserConfig_groups(rUserPoolName, rUserName, rGroupName, "[0, 1]"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					testAccCheckUserInGroup(ctx, resourceName, rGroupName+"-0"),
+					testAccCheckUserInGroup(ctx, resourceName, rGroupName+"-1"),
+					resource.TestCheckResourceAttr(resourceName, "groups.#", "2"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "groups.*", rGroupName+"-0"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "groups.*", rGroupName+"-1"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"groups",
+				},
+			},
+			{
+				Config: testAccUserConfig_groups(rUserPoolName, rUserName, rGroupName, "[1, 2]"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					testAccCheckUserInGroup(ctx, resourceName, rGroupName+"-1"),
+					testAccCheckUserInGroup(ctx, resourceName, rGroupName+"-2"),
+					resource.TestCheckResourceAttr(resourceName, "groups.#", "2"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "groups.*", rGroupName+"-1"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "groups.*", rGroupName+"-2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_groupsNotFound(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rGroupName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccUserConfig_groupsNotFound(rUserPoolName, rUserName, rGroupName),
+				ExpectError: regexp.MustCompile(`groups not found in Cognito User Pool \(.+\): ` + rGroupName),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUser_mfaSettings(t *testing.T) {
+	ctx := acctest.Context(t)
+	rUserPoolName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	rUserName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	resourceName := "aws_cognito_user.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckUserDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccUserConfig_mfaSettings(rUserPoolName, rUserName, false, true),
+				ExpectError: regexp.MustCompile(`sms_mfa_settings: preferred requires enabled to be true`),
+			},
+			{
+				Config: testAccUserConfig_mfaSettings(rUserPoolName, rUserName, false, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckUserExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "sms_mfa_settings.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "sms_mfa_settings.0.enabled", "false"),
+					resource.TestCheckResourceAttr(resourceName, "sms_mfa_settings.0.preferred", "false"),
+					resource.TestCheckResourceAttr(resourceName, "software_token_mfa_settings.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "software_token_mfa_settings.0.enabled", "false"),
+					resource.TestCheckResourceAttr(resourceName, "preferred_mfa_setting", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestMergeUserAttributesJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		Document   string
+		Attributes map[string]interface{}
+		Expected   map[string]interface{}
+		ExpectErr  bool
+	}{
+		{
+			TestName: "empty",
+			Expected: map[string]interface{}{},
+		},
+		{
+			TestName:   "disjoint",
+			Document:   `{"one":"1"}`,
+			Attributes: map[string]interface{}{"two": "2"},
+			Expected:   map[string]interface{}{"one": "1", "two": "2"},
+		},
+		{
+			TestName:   "same key",
+			Document:   `{"one":"1","two":"2","email":"a@example.com"}`,
+			Attributes: map[string]interface{}{"two": "two", "email": "b@example.com"},
+			ExpectErr:  true,
+		},
+		{
+			TestName:   "custom prefix",
+			Document:   `{"custom:one":"1"}`,
+			Attributes: map[string]interface{}{"one": "one"},
+			ExpectErr:  true,
+		},
+		{
+			TestName:  "invalid document",
+			Document:  `{"one":1}`,
+			ExpectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tfcognitoidp.MergeUserAttributesJSON(testCase.Document, testCase.Attributes)
+
+			if testCase.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestMergeUserAttributes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		Document   string
+		Attributes map[string]interface{}
+		Expected   map[string]interface{}
+		ExpectErr  bool
+	}{
+		{
+			TestName: "empty",
+			Expected: map[string]interface{}{},
+		},
+		{
+			TestName: "document only",
+			Document: `{"one":"1","two":"2"}`,
+			Expected: map[string]interface{}{"one": "1", "two": "2"},
+		},
+		{
+			TestName:   "explicit wins",
+			Document:   `{"one":"1","two":"2"}`,
+			Attributes: map[string]interface{}{"two": "two", "three": "3"},
+			Expected:   map[string]interface{}{"one": "1", "two": "two", "three": "3"},
+		},
+		{
+			TestName:  "nested value",
+			Document:  `{"one":{"two":"2"}}`,
+			ExpectErr: true,
+		},
+		{
+			TestName:  "non-string value",
+			Document:  `{"one":1}`,
+			ExpectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tfcognitoidp.MergeUserAttributes(testCase.Document, testCase.Attributes)
+
+			if testCase.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func testAccCheckUserAttribute(ctx context.Context, n, name, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+
+		user, err := tfcognitoidp.FindUserByTwoPartKey(ctx, conn, rs.Primary.Attributes["user_pool_id"], rs.Primary.Attributes["username"])
+
+		if err != nil {
+			return err
+		}
+
+		for _, v := range user.UserAttributes {
+			if aws.StringValue(v.Name) == name {
+				if got := aws.StringValue(v.Value); got != value {
+					return fmt.Errorf("Cognito User %s attribute %s: got %q, expected %q", rs.Primary.ID, name, got, value)
+				}
+
+				return nil
+			}
+		}
+
+		return fmt.Errorf("Cognito User %s attribute %s not found", rs.Primary.ID, name)
+	}
+}
+
+func TestUserAttributesPropagated(t *testing.T) {
+	t.Parallel()
+
+	current := []*cognitoidentityprovider.AttributeType{
+		{Name: aws.String("email"), Value: aws.String("test@example.com")},
+		{Name: aws.String("custom:one"), Value: aws.String("1")},
+	}
+
+	testCases := []struct {
+		TestName string
+		Updated  []*cognitoidentityprovider.AttributeType
+		Deleted  []*string
+		Expected bool
+	}{
+		{
+			TestName: "no changes",
+			Expected: true,
+		},
+		{
+			TestName: "updated value visible",
+			Updated:  []*cognitoidentityprovider.AttributeType{{Name: aws.String("custom:one"), Value: aws.String("1")}},
+			Expected: true,
+		},
+		{
+			TestName: "updated value stale",
+			Updated:  []*cognitoidentityprovider.AttributeType{{Name: aws.String("custom:one"), Value: aws.String("one")}},
+			Expected: false,
+		},
+		{
+			TestName: "updated value missing",
+			Updated:  []*cognitoidentityprovider.AttributeType{{Name: aws.String("custom:two"), Value: aws.String("2")}},
+			Expected: false,
+		},
+		{
+			TestName: "deleted attribute gone",
+			Deleted:  aws.StringSlice([]string{"custom:two"}),
+			Expected: true,
+		},
+		{
+			TestName: "deleted attribute still present",
+			Deleted:  aws.StringSlice([]string{"custom:one"}),
+			Expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfcognitoidp.UserAttributesPropagated(current, testCase.Updated, testCase.Deleted); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserAttributeServerAuthoritativeSuppress(t *testing.T) {
+	t.Parallel()
+
+	seeded := map[string]interface{}{"one": "1"}
+
+	testCases := []struct {
+		TestName string
+		Name     string
+		New      string
+		Expected bool
+	}{
+		{
+			TestName: "config unchanged",
+			Name:     "one",
+			New:      "1",
+			Expected: true,
+		},
+		{
+			TestName: "config changed",
+			Name:     "one",
+			New:      "2",
+			Expected: false,
+		},
+		{
+			TestName: "config removed",
+			Name:     "one",
+			New:      "",
+			Expected: false,
+		},
+		{
+			TestName: "server-owned",
+			Name:     "two",
+			New:      "",
+			Expected: true,
+		},
+		{
+			TestName: "config added",
+			Name:     "two",
+			New:      "2",
+			Expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfcognitoidp.UserAttributeServerAuthoritativeSuppress(testCase.Name, testCase.New, seeded); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserAttributeKeysNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		Attributes map[string]interface{}
+		Allowed    []string
+		Expected   []string
+	}{
+		{
+			TestName:   "all allowed",
+			Attributes: map[string]interface{}{"email": "test@example.com", "one": "1"},
+			Allowed:    []string{"email", "one"},
+		},
+		{
+			TestName:   "custom prefix normalized",
+			Attributes: map[string]interface{}{"custom:one": "1", "two": "2"},
+			Allowed:    []string{"one", "custom:two"},
+		},
+		{
+			TestName:   "disallowed",
+			Attributes: map[string]interface{}{"email": "test@example.com", "one": "1", "two": "2"},
+			Allowed:    []string{"one"},
+			Expected:   []string{"email", "two"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserAttributeKeysNotAllowed(testCase.Attributes, testCase.Allowed)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestRetryUserOperation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName      string
+		Deadline      time.Duration
+		ExpectedCalls func(int) bool
+	}{
+		{
+			TestName:      "no budget",
+			ExpectedCalls: func(calls int) bool { return calls == 1 },
+		},
+		{
+			TestName:      "budget exhausted",
+			Deadline:      -1 * time.Second,
+			ExpectedCalls: func(calls int) bool { return calls == 1 },
+		},
+		{
+			TestName:      "budget",
+			Deadline:      3 * time.Second,
+			ExpectedCalls: func(calls int) bool { return calls > 1 },
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			var deadline time.Time
+			if testCase.Deadline != 0 {
+				deadline = time.Now().Add(testCase.Deadline)
+			}
+
+			calls := 0
+			start := time.Now()
+			_, err := tfcognitoidp.RetryUserOperation(context.Background(), deadline, func() (interface{}, error) {
+				calls++
+				return nil, awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "Too many requests", nil)
+			})
+
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			if !testCase.ExpectedCalls(calls) {
+				t.Errorf("unexpected number of calls: %d", calls)
+			}
+
+			if elapsed := time.Since(start); elapsed > testCase.Deadline+5*time.Second {
+				t.Errorf("retries exceeded budget: %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryUserOperationTransientError(t *testing.T) {
+	t.Parallel()
+
+	for _, code := range []string{cognitoidentityprovider.ErrCodeInternalErrorException, cognitoidentityprovider.ErrCodeTooManyRequestsException} {
+		code := code
+		t.Run(code, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			output, err := tfcognitoidp.RetryUserOperation(context.Background(), time.Now().Add(30*time.Second), func() (interface{}, error) {
+				calls++
+				if calls == 1 {
+					return nil, awserr.New(code, "transient", nil)
+				}
+				return &cognitoidentityprovider.AdminCreateUserOutput{}, nil
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if _, ok := output.(*cognitoidentityprovider.AdminCreateUserOutput); !ok {
+				t.Errorf("unexpected output: %T", output)
+			}
+
+			if calls != 2 {
+				t.Errorf("got %d calls, expected 2", calls)
+			}
+		})
+	}
+}
+
+func TestRetryUserOperationContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	_, err := tfcognitoidp.RetryUserOperation(ctx, time.Now().Add(30*time.Second), func() (interface{}, error) {
+		calls++
+		if calls == 1 {
+			cancel()
+		}
+		return nil, awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "Too many requests", nil)
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, expected %s", err, context.Canceled)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("retries continued after cancellation: %s", elapsed)
+	}
+
+	calls = 0
+	_, err = tfcognitoidp.RetryUserOperation(ctx, time.Time{}, func() (interface{}, error) {
+		calls++
+		return nil, nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, expected %s", err, context.Canceled)
+	}
+
+	if calls != 0 {
+		t.Errorf("got %d calls after cancellation, expected 0", calls)
+	}
+}
+
+func TestRetryUserOperationDisableThrottled(t *testing.T) {
+	t.Parallel()
+
+	enabled := true
+	calls := 0
+
+	_, err := tfcognitoidp.RetryUserOperation(context.Background(), time.Now().Add(30*time.Second), func() (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "Too many requests", nil)
+		}
+		enabled = false
+		return &cognitoidentityprovider.AdminDisableUserOutput{}, nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if enabled {
+		t.Error("expected user to be disabled")
+	}
+}
+
+func TestUserStatusTransitionError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName             string
+		Current              string
+		Desired              string
+		HasPassword          bool
+		HasTemporaryPassword bool
+		ExpectError          bool
+	}{
+		{
+			TestName:    "confirmed with password",
+			Current:     cognitoidentityprovider.UserStatusTypeForceChangePassword,
+			Desired:     cognitoidentityprovider.UserStatusTypeConfirmed,
+			HasPassword: true,
+		},
+		{
+			TestName:    "confirmed without password",
+			Current:     cognitoidentityprovider.UserStatusTypeForceChangePassword,
+			Desired:     cognitoidentityprovider.UserStatusTypeConfirmed,
+			ExpectError: true,
+		},
+		{
+			TestName:             "force change password with temporary password",
+			Current:              cognitoidentityprovider.UserStatusTypeConfirmed,
+			Desired:              cognitoidentityprovider.UserStatusTypeForceChangePassword,
+			HasTemporaryPassword: true,
+		},
+		{
+			TestName:    "force change password without temporary password",
+			Current:     cognitoidentityprovider.UserStatusTypeConfirmed,
+			Desired:     cognitoidentityprovider.UserStatusTypeForceChangePassword,
+			HasPassword: true,
+			ExpectError: true,
+		},
+		{
+			TestName: "reset required from confirmed",
+			Current:  cognitoidentityprovider.UserStatusTypeConfirmed,
+			Desired:  cognitoidentityprovider.UserStatusTypeResetRequired,
+		},
+		{
+			TestName:    "reset required from force change password",
+			Current:     cognitoidentityprovider.UserStatusTypeForceChangePassword,
+			Desired:     cognitoidentityprovider.UserStatusTypeResetRequired,
+			ExpectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			err := tfcognitoidp.UserStatusTransitionError(testCase.Current, testCase.Desired, testCase.HasPassword, testCase.HasTemporaryPassword)
+
+			if err == nil && testCase.ExpectError {
+				t.Fatal("expected error")
+			}
+
+			if err != nil && !testCase.ExpectError {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestUserMFASettingsError(t *testing.T) {
+	t.Parallel()
+
+	settings := func(enabled, preferred bool) []interface{} {
+		return []interface{}{map[string]interface{}{"enabled": enabled, "preferred": preferred}}
+	}
+
+	testCases := []struct {
+		TestName      string
+		SMS           []interface{}
+		SoftwareToken []interface{}
+		ExpectError   bool
+	}{
+		{
+			TestName: "none",
+		},
+		{
+			TestName:      "enabled and preferred",
+			SMS:           settings(true, false),
+			SoftwareToken: settings(true, true),
+		},
+		{
+			TestName:    "preferred not enabled",
+			SMS:         settings(false, true),
+			ExpectError: true,
+		},
+		{
+			TestName:      "both preferred",
+			SMS:           settings(true, true),
+			SoftwareToken: settings(true, true),
+			ExpectError:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			err := tfcognitoidp.UserMFASettingsError(testCase.SMS, testCase.SoftwareToken)
+
+			if got, want := err != nil, testCase.ExpectError; got != want {
+				t.Errorf("got error %v, expected error %t", err, want)
+			}
+		})
+	}
+}
+
+func TestUserMFAEnrolledAt(t *testing.T) {
+	t.Parallel()
+
+	lastModified := time.Date(2023, time.February, 1, 12, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		TestName string
+		User     *cognitoidentityprovider.AdminGetUserOutput
+		Expected string
+	}{
+		{
+			TestName: "nil",
+		},
+		{
+			TestName: "no mfa",
+			User: &cognitoidentityprovider.AdminGetUserOutput{
+				UserLastModifiedDate: aws.Time(lastModified),
+			},
+		},
+		{
+			TestName: "mfa preference change",
+			User: &cognitoidentityprovider.AdminGetUserOutput{
+				PreferredMfaSetting:  aws.String(cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa),
+				UserLastModifiedDate: aws.Time(lastModified),
+				UserMFASettingList:   aws.StringSlice([]string{cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa}),
+			},
+			Expected: "2023-02-01T12:30:00Z",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfcognitoidp.UserMFAEnrolledAt(testCase.User); got != testCase.Expected {
+				t.Errorf("got %q, expected %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserAttributesNotInSchema(t *testing.T) {
+	t.Parallel()
+
+	names := map[string]struct{}{
+		"email":      {},
+		"custom:one": {},
+	}
+
+	testCases := []struct {
+		TestName   string
+		Attributes map[string]interface{}
+		Expected   []string
+	}{
+		{
+			TestName:   "declared",
+			Attributes: map[string]interface{}{"email": "test@example.com", "one": "1", "custom:one": "1"},
+		},
+		{
+			TestName:   "standard attributes not checked",
+			Attributes: map[string]interface{}{"phone_number": "+15555555555"},
+		},
+		{
+			TestName:   "undeclared",
+			Attributes: map[string]interface{}{"one": "1", "tow": "2", "custom:three": "3"},
+			Expected:   []string{"custom:three", "custom:tow"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserAttributesNotInSchema(testCase.Attributes, names)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserAttributeAPIName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName    string
+		Key         string
+		Expected    string
+		ExpectedKey string
+	}{
+		{
+			TestName:    "standard",
+			Key:         "email",
+			Expected:    "email",
+			ExpectedKey: "email",
+		},
+		{
+			TestName:    "custom",
+			Key:         "one",
+			Expected:    "custom:one",
+			ExpectedKey: "one",
+		},
+		{
+			TestName:    "custom prefixed",
+			Key:         "custom:one",
+			Expected:    "custom:one",
+			ExpectedKey: "one",
+		},
+		{
+			TestName:    "developer only",
+			Key:         "dev:one",
+			Expected:    "dev:custom:one",
+			ExpectedKey: "dev:one",
+		},
+		{
+			TestName:    "developer only custom prefixed",
+			Key:         "dev:custom:one",
+			Expected:    "dev:custom:one",
+			ExpectedKey: "dev:one",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserAttributeAPIName(testCase.Key)
+
+			if got != testCase.Expected {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+
+			if got := tfcognitoidp.UserAttributeKey(got); got != testCase.ExpectedKey {
+				t.Errorf("got key %s, expected %s", got, testCase.ExpectedKey)
+			}
+		})
+	}
+}
+
+func TestUserParseImportID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName         string
+		ID               string
+		ExpectedPoolID   string
+		ExpectedUsername string
+		ExpectError      bool
+	}{
+		{
+			TestName:    "empty",
+			ExpectError: true,
+		},
+		{
+			TestName:    "no separator",
+			ID:          "us-east-1_vG78M4goG",
+			ExpectError: true,
+		},
+		{
+			TestName:    "no username",
+			ID:          "us-east-1_vG78M4goG/",
+			ExpectError: true,
+		},
+		{
+			TestName:    "no user pool ID",
+			ID:          ":user",
+			ExpectError: true,
+		},
+		{
+			TestName:         "slash",
+			ID:               "us-east-1_vG78M4goG/user",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "user",
+		},
+		{
+			TestName:         "colon",
+			ID:               "us-east-1_vG78M4goG:user",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "user",
+		},
+		{
+			TestName:         "slash in username",
+			ID:               "us-east-1_vG78M4goG/idp/user",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "idp/user",
+		},
+		{
+			TestName:         "colon separator with slash in username",
+			ID:               "us-east-1_vG78M4goG:idp/user",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "idp/user",
+		},
+		{
+			TestName:         "slash separator with colon in username",
+			ID:               "us-east-1_vG78M4goG/idp:user",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "idp:user",
+		},
+		{
+			TestName:         "encoded slash in username",
+			ID:               "us-east-1_vG78M4goG/idp%2Fuser",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "idp/user",
+		},
+		{
+			TestName:         "colon separator with percent in username",
+			ID:               "us-east-1_vG78M4goG:50%off",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "50%off",
+		},
+		{
+			TestName:         "encoded percent in username",
+			ID:               "us-east-1_vG78M4goG/50%25off",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "50%off",
+		},
+		{
+			TestName:         "slash separator with percent in username",
+			ID:               "us-east-1_vG78M4goG/50%off",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "50%off",
+		},
+		{
+			TestName:         "slash separator with invalid encoding in username",
+			ID:               "us-east-1_vG78M4goG/50%zz",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "50%zz",
+		},
+		{
+			TestName:         "slash separator with slash and percent in username",
+			ID:               "us-east-1_vG78M4goG/idp/50%2Foff",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "idp/50%2Foff",
+		},
+		{
+			TestName:         "slash separator with space in username",
+			ID:               "us-east-1_vG78M4goG/user name",
+			ExpectedPoolID:   "us-east-1_vG78M4goG",
+			ExpectedUsername: "user name",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			gotPoolID, gotUsername, err := tfcognitoidp.UserParseImportID(testCase.ID)
+
+			if err == nil && testCase.ExpectError {
+				t.Fatalf("expected error")
+			}
+
+			if err != nil && !testCase.ExpectError {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if gotPoolID != testCase.ExpectedPoolID || gotUsername != testCase.ExpectedUsername {
+				t.Errorf("got %s, %s, expected %s, %s", gotPoolID, gotUsername, testCase.ExpectedPoolID, testCase.ExpectedUsername)
+			}
+		})
+	}
+}
+
+func TestUserCreateResourceID(t *testing.T) {
+	t.Parallel()
+
+	for _, username := range []string{"user", "idp/user", "idp:user", "50%off", "user name", "user@example.com"} {
+		username := username
+		t.Run(username, func(t *testing.T) {
+			t.Parallel()
+
+			id := tfcognitoidp.UserCreateResourceID("us-east-1_vG78M4goG", username)
+
+			if got, want := strings.Count(id, "/"), 1; got != want {
+				t.Errorf("got %d separators in %q, expected %d", got, id, want)
+			}
+
+			gotPoolID, gotUsername, err := tfcognitoidp.UserParseImportID(id)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if gotPoolID != "us-east-1_vG78M4goG" || gotUsername != username {
+				t.Errorf("got %s, %s, expected %s, %s", gotPoolID, gotUsername, "us-east-1_vG78M4goG", username)
+			}
+		})
+	}
+}
+
+func TestUserCreateDivergence(t *testing.T) {
+	t.Parallel()
+
+	user := &cognitoidentityprovider.AdminGetUserOutput{
+		Enabled: aws.Bool(true),
+		UserAttributes: []*cognitoidentityprovider.AttributeType{
+			{Name: aws.String("email"), Value: aws.String("test@example.com")},
+			{Name: aws.String("email_verified"), Value: aws.String("true")},
+			{Name: aws.String("custom:one"), Value: aws.String("1")},
+			{Name: aws.String("sub"), Value: aws.String("7f3a1d0e-0000-4000-8000-000000000001")},
+		},
+		UserStatus: aws.String(cognitoidentityprovider.UserStatusTypeForceChangePassword),
+	}
+
+	testCases := []struct {
+		TestName        string
+		Attributes      map[string]interface{}
+		Enabled         bool
+		DesiredStatus   string
+		Expected        []string
+		ExpectedMissing []string
+	}{
+		{
+			TestName:   "matches",
+			Attributes: map[string]interface{}{"email": "test@example.com", "one": "1"},
+			Enabled:    true,
+		},
+		{
+			TestName:      "matches desired status",
+			Attributes:    map[string]interface{}{"custom:one": "1"},
+			Enabled:       true,
+			DesiredStatus: cognitoidentityprovider.UserStatusTypeForceChangePassword,
+		},
+		{
+			TestName:   "attribute altered",
+			Attributes: map[string]interface{}{"email": "test@example.com", "email_verified": "false"},
+			Enabled:    true,
+			Expected:   []string{`attribute email_verified: expected "false", got "true"`},
+		},
+		{
+			TestName:        "attribute not set",
+			Attributes:      map[string]interface{}{"two": "2"},
+			Enabled:         true,
+			ExpectedMissing: []string{`attribute custom:two: expected "2", not set`},
+		},
+		{
+			TestName:        "attribute altered and not set",
+			Attributes:      map[string]interface{}{"one": "2", "two": "2"},
+			Enabled:         true,
+			Expected:        []string{`attribute custom:one: expected "2", got "1"`},
+			ExpectedMissing: []string{`attribute custom:two: expected "2", not set`},
+		},
+		{
+			TestName:      "enabled and status",
+			Enabled:       false,
+			DesiredStatus: cognitoidentityprovider.UserStatusTypeConfirmed,
+			Expected: []string{
+				"enabled: expected false, got true",
+				"status: expected CONFIRMED, got FORCE_CHANGE_PASSWORD",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotMissing := tfcognitoidp.UserCreateDivergence(user, testCase.Attributes, false, testCase.Enabled, testCase.DesiredStatus)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+
+			if !reflect.DeepEqual(gotMissing, testCase.ExpectedMissing) {
+				t.Errorf("got missing %v, expected %v", gotMissing, testCase.ExpectedMissing)
+			}
+		})
+	}
+}
+
+func TestUserPoolUsernameCaseSensitive(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName string
+		UserPool *cognitoidentityprovider.UserPoolType
+		Expected bool
+	}{
+		{
+			TestName: "nil",
+			Expected: true,
+		},
+		{
+			TestName: "no username configuration",
+			UserPool: &cognitoidentityprovider.UserPoolType{},
+			Expected: true,
+		},
+		{
+			TestName: "case sensitive",
+			UserPool: &cognitoidentityprovider.UserPoolType{
+				UsernameConfiguration: &cognitoidentityprovider.UsernameConfigurationType{CaseSensitive: aws.Bool(true)},
+			},
+			Expected: true,
+		},
+		{
+			TestName: "case insensitive",
+			UserPool: &cognitoidentityprovider.UserPoolType{
+				UsernameConfiguration: &cognitoidentityprovider.UsernameConfigurationType{CaseSensitive: aws.Bool(false)},
+			},
+			Expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfcognitoidp.UserPoolUsernameCaseSensitive(testCase.UserPool); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserAttributeUpdateBatches(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		Attributes map[string]interface{}
+		Order      []string
+		Expected   []map[string]interface{}
+	}{
+		{
+			TestName: "no attributes",
+			Order:    []string{"email"},
+		},
+		{
+			TestName:   "no order",
+			Attributes: map[string]interface{}{"email": "test@example.com", "email_verified": "true", "one": "1"},
+			Expected: []map[string]interface{}{
+				{"email": "test@example.com", "email_verified": "true", "one": "1"},
+			},
+		},
+		{
+			TestName:   "ordered",
+			Attributes: map[string]interface{}{"email": "test@example.com", "email_verified": "true", "one": "1", "two": "2"},
+			Order:      []string{"email", "email_verified"},
+			Expected: []map[string]interface{}{
+				{"email": "test@example.com"},
+				{"email_verified": "true"},
+				{"one": "1", "two": "2"},
+			},
+		},
+		{
+			TestName:   "reverse ordered",
+			Attributes: map[string]interface{}{"email": "test@example.com", "email_verified": "true"},
+			Order:      []string{"email_verified", "email"},
+			Expected: []map[string]interface{}{
+				{"email_verified": "true"},
+				{"email": "test@example.com"},
+			},
+		},
+		{
+			TestName:   "custom prefix and unchanged keys",
+			Attributes: map[string]interface{}{"one": "1", "two": "2"},
+			Order:      []string{"phone_number", "custom:two", "one"},
+			Expected: []map[string]interface{}{
+				{"two": "2"},
+				{"one": "1"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserAttributeUpdateBatches(testCase.Attributes, testCase.Order)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestFlattenUserAttributeList(t *testing.T) {
+	t.Parallel()
+
+	apiList := []*cognitoidentityprovider.AttributeType{
+		{Name: aws.String("sub"), Value: aws.String("7f3a1d0e-0000-4000-8000-000000000001")},
+		{Name: aws.String("custom:one"), Value: aws.String("1")},
+		nil,
+		{Name: aws.String("dev:custom:two"), Value: aws.String("2")},
+		{Name: aws.String("email"), Value: aws.String("Test@Example.com")},
+	}
+
+	want := []interface{}{
+		map[string]interface{}{"name": "custom:one", "value": "1"},
+		map[string]interface{}{"name": "dev:custom:two", "value": "2"},
+		map[string]interface{}{"name": "email", "value": "Test@Example.com"},
+		map[string]interface{}{"name": "sub", "value": "7f3a1d0e-0000-4000-8000-000000000001"},
+	}
+
+	if got := tfcognitoidp.FlattenUserAttributeList(apiList); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+}
+
+func TestUserPasswordPolicyError(t *testing.T) {
+	t.Parallel()
+
+	policy := &cognitoidentityprovider.PasswordPolicyType{
+		MinimumLength:    aws.Int64(8),
+		RequireLowercase: aws.Bool(true),
+		RequireNumbers:   aws.Bool(true),
+		RequireSymbols:   aws.Bool(true),
+		RequireUppercase: aws.Bool(true),
+	}
+
+	testCases := []struct {
+		TestName      string
+		Policy        *cognitoidentityprovider.PasswordPolicyType
+		Password      string
+		ExpectedError string
+	}{
+		{
+			TestName: "no policy",
+			Password: "a",
+		},
+		{
+			TestName: "valid",
+			Policy:   policy,
+			Password: "Passw0rd!",
+		},
+		{
+			TestName:      "too short",
+			Policy:        policy,
+			Password:      "Pa0!",
+			ExpectedError: "at least 8 characters",
+		},
+		{
+			TestName:      "no lowercase",
+			Policy:        policy,
+			Password:      "PASSW0RD!",
+			ExpectedError: "a lowercase letter",
+		},
+		{
+			TestName:      "no uppercase",
+			Policy:        policy,
+			Password:      "passw0rd!",
+			ExpectedError: "an uppercase letter",
+		},
+		{
+			TestName:      "no number",
+			Policy:        policy,
+			Password:      "Password!",
+			ExpectedError: "a number",
+		},
+		{
+			TestName:      "no symbol",
+			Policy:        policy,
+			Password:      "Passw0rd",
+			ExpectedError: "a symbol",
+		},
+		{
+			TestName: "requirements not set",
+			Policy:   &cognitoidentityprovider.PasswordPolicyType{MinimumLength: aws.Int64(6)},
+			Password: "simple",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			err := tfcognitoidp.UserPasswordPolicyError(testCase.Policy, testCase.Password)
+
+			if testCase.ExpectedError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected error containing %q", testCase.ExpectedError)
+			}
+
+			if !strings.Contains(err.Error(), testCase.ExpectedError) {
+				t.Errorf("got %q, expected error containing %q", err, testCase.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestUserCreateAdoptsExisting(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName      string
+		Err           error
+		MessageAction string
+		Expected      bool
+	}{
+		{
+			TestName:      "no error",
+			MessageAction: cognitoidentityprovider.MessageActionTypeResend,
+		},
+		{
+			TestName:      "resend existing",
+			Err:           awserr.New(cognitoidentityprovider.ErrCodeUsernameExistsException, "User account already exists", nil),
+			MessageAction: cognitoidentityprovider.MessageActionTypeResend,
+			Expected:      true,
+		},
+		{
+			TestName: "create existing",
+			Err:      awserr.New(cognitoidentityprovider.ErrCodeUsernameExistsException, "User account already exists", nil),
+		},
+		{
+			TestName:      "suppress existing",
+			Err:           awserr.New(cognitoidentityprovider.ErrCodeUsernameExistsException, "User account already exists", nil),
+			MessageAction: cognitoidentityprovider.MessageActionTypeSuppress,
+		},
+		{
+			TestName:      "resend other error",
+			Err:           awserr.New(cognitoidentityprovider.ErrCodeUserNotFoundException, "User does not exist", nil),
+			MessageAction: cognitoidentityprovider.MessageActionTypeResend,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfcognitoidp.UserCreateAdoptsExisting(testCase.Err, testCase.MessageAction); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeUserPhoneNumber(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName       string
+		Value          string
+		DefaultCountry string
+		Expected       string
+		ExpectError    bool
+	}{
+		{
+			TestName: "E.164",
+			Value:    "+15555550100",
+			Expected: "+15555550100",
+		},
+		{
+			TestName: "separators",
+			Value:    "+1 (555) 555-0100",
+			Expected: "+15555550100",
+		},
+		{
+			TestName:    "no country code",
+			Value:       "5555550100",
+			ExpectError: true,
+		},
+		{
+			TestName:       "default country",
+			Value:          "555.555.0100",
+			DefaultCountry: "1",
+			Expected:       "+15555550100",
+		},
+		{
+			TestName:       "default country with plus",
+			Value:          "7700 900123",
+			DefaultCountry: "+44",
+			Expected:       "+447700900123",
+		},
+		{
+			TestName:       "default country not applied to E.164",
+			Value:          "+447700900123",
+			DefaultCountry: "1",
+			Expected:       "+447700900123",
+		},
+		{
+			TestName:    "letters",
+			Value:       "+1555CALLNOW",
+			ExpectError: true,
+		},
+		{
+			TestName:    "too long",
+			Value:       "+1234567890123456",
+			ExpectError: true,
+		},
+		{
+			TestName:    "leading zero country code",
+			Value:       "+05555550100",
+			ExpectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tfcognitoidp.NormalizeUserPhoneNumber(testCase.Value, testCase.DefaultCountry)
+
+			if got, want := err != nil, testCase.ExpectError; got != want {
+				t.Fatalf("got error %v, expected error %t", err, want)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got %q, expected %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestApplyUserAttributesUpdate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName        string
+		Batches         []map[string]interface{}
+		Delete          []*string
+		UpdateErrorAt   int
+		DeleteError     bool
+		Expected        map[string]interface{}
+		ExpectedDeletes int
+		ExpectError     bool
+	}{
+		{
+			TestName: "no changes",
+			Expected: map[string]interface{}{},
+		},
+		{
+			TestName: "update and delete",
+			Batches: []map[string]interface{}{
+				{"email": "test@example.com"},
+				{"name": "test"},
+			},
+			Delete:          aws.StringSlice([]string{"family_name"}),
+			Expected:        map[string]interface{}{"email": "test@example.com", "name": "test"},
+			ExpectedDeletes: 1,
+		},
+		{
+			TestName: "delete fails after update",
+			Batches: []map[string]interface{}{
+				{"email": "test@example.com", "name": "test"},
+			},
+			Delete:          aws.StringSlice([]string{"family_name"}),
+			DeleteError:     true,
+			Expected:        map[string]interface{}{"email": "test@example.com", "name": "test"},
+			ExpectedDeletes: 1,
+			ExpectError:     true,
+		},
+		{
+			TestName: "second batch fails",
+			Batches: []map[string]interface{}{
+				{"email": "test@example.com"},
+				{"name": "test"},
+			},
+			Delete:        aws.StringSlice([]string{"family_name"}),
+			UpdateErrorAt: 2,
+			Expected:      map[string]interface{}{"email": "test@example.com"},
+			ExpectError:   true,
+		},
+		{
+			TestName: "first batch fails",
+			Batches: []map[string]interface{}{
+				{"email": "test@example.com"},
+			},
+			UpdateErrorAt: 1,
+			Expected:      map[string]interface{}{},
+			ExpectError:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			updates, deletes := 0, 0
+			update := func(map[string]interface{}) error {
+				updates++
+				if updates == testCase.UpdateErrorAt {
+					return errors.New("update failed")
+				}
+				return nil
+			}
+			remove := func([]*string) error {
+				deletes++
+				if testCase.DeleteError {
+					return errors.New("delete failed")
+				}
+				return nil
+			}
+
+			applied, err := tfcognitoidp.ApplyUserAttributesUpdate(testCase.Batches, testCase.Delete, update, remove)
+
+			if got, want := err != nil, testCase.ExpectError; got != want {
+				t.Fatalf("got error %v, expected error %t", err, want)
+			}
+
+			if !reflect.DeepEqual(applied, testCase.Expected) {
+				t.Errorf("got applied %v, expected %v", applied, testCase.Expected)
+			}
+
+			if deletes != testCase.ExpectedDeletes {
+				t.Errorf("got %d deletes, expected %d", deletes, testCase.ExpectedDeletes)
+			}
+		})
+	}
+}
+
+func TestReconcileUserAttributes(t *testing.T) {
+	t.Parallel()
+
+	attribute := func(name, value string) *cognitoidentityprovider.AttributeType {
+		return &cognitoidentityprovider.AttributeType{Name: aws.String(name), Value: aws.String(value)}
+	}
+
+	testCases := []struct {
+		TestName string
+		Get      []*cognitoidentityprovider.AttributeType
+		Listed   []*cognitoidentityprovider.AttributeType
+		Expected []*cognitoidentityprovider.AttributeType
+	}{
+		{
+			TestName: "consistent",
+			Get:      []*cognitoidentityprovider.AttributeType{attribute("sub", "1"), attribute("email", "test@example.com")},
+			Listed:   []*cognitoidentityprovider.AttributeType{attribute("sub", "1"), attribute("email", "test@example.com")},
+			Expected: []*cognitoidentityprovider.AttributeType{attribute("sub", "1"), attribute("email", "test@example.com")},
+		},
+		{
+			TestName: "deleted attribute",
+			Get:      []*cognitoidentityprovider.AttributeType{attribute("sub", "1"), attribute("email", "test@example.com"), attribute("custom:foo", "bar")},
+			Listed:   []*cognitoidentityprovider.AttributeType{attribute("sub", "1"), attribute("email", "test@example.com")},
+			Expected: []*cognitoidentityprovider.AttributeType{attribute("sub", "1"), attribute("email", "test@example.com")},
+		},
+		{
+			TestName: "sub kept",
+			Get:      []*cognitoidentityprovider.AttributeType{attribute("sub", "1"), attribute("email", "test@example.com")},
+			Listed:   []*cognitoidentityprovider.AttributeType{attribute("email", "test@example.com")},
+			Expected: []*cognitoidentityprovider.AttributeType{attribute("sub", "1"), attribute("email", "test@example.com")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.ReconcileUserAttributes(testCase.Get, testCase.Listed)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestExpandFlattenUserTagAttributes(t *testing.T) {
+	t.Parallel()
+
+	tags := map[string]interface{}{
+		"env":  "test",
+		"team": "identity",
+	}
+
+	attributes := tfcognitoidp.ExpandUserTagAttributes(tags)
+	expected := map[string]interface{}{
+		"custom:tag_env":  "test",
+		"custom:tag_team": "identity",
+	}
+
+	if !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("got %v, expected %v", attributes, expected)
+	}
+
+	attributes["email"] = "test@example.com"
+	attributes["custom:foo"] = "bar"
+
+	if got := tfcognitoidp.FlattenUserTagAttributes(attributes); !reflect.DeepEqual(got, tags) {
+		t.Errorf("got tags %v, expected %v", got, tags)
+	}
+
+	if expected := map[string]interface{}{"email": "test@example.com", "custom:foo": "bar"}; !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("got attributes %v, expected %v", attributes, expected)
+	}
+}
+
+func TestUserAttributeKeysWithTagPrefix(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		Attributes map[string]interface{}
+		Expected   []string
+	}{
+		{
+			TestName:   "no attributes",
+			Attributes: map[string]interface{}{},
+		},
+		{
+			TestName: "no collision",
+			Attributes: map[string]interface{}{
+				"email":      "test@example.com",
+				"custom:tag": "value",
+				"tagline":    "value",
+			},
+		},
+		{
+			TestName: "collision",
+			Attributes: map[string]interface{}{
+				"email":          "test@example.com",
+				"tag_env":        "test",
+				"custom:tag_app": "test",
+			},
+			Expected: []string{"custom:tag_app", "tag_env"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserAttributeKeysWithTagPrefix(testCase.Attributes)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserConfirmSignUpError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName    string
+		Err         error
+		ExpectError bool
+	}{
+		{
+			TestName: "no error",
+		},
+		{
+			TestName: "already confirmed",
+			Err:      awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "User cannot be confirmed. Current status is CONFIRMED", nil),
+		},
+		{
+			TestName:    "other error",
+			Err:         awserr.New(cognitoidentityprovider.ErrCodeUserNotFoundException, "User does not exist.", nil),
+			ExpectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			err := tfcognitoidp.UserConfirmSignUpError(testCase.Err)
+
+			if got, want := err != nil, testCase.ExpectError; got != want {
+				t.Errorf("got error %v, expected error %t", err, want)
+			}
+		})
+	}
+}
+
+func TestFlattenUserIdentities(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName    string
+		Value       interface{}
+		Expected    []interface{}
+		ExpectError bool
+	}{
+		{
+			TestName: "not set",
+		},
+		{
+			TestName: "empty array",
+			Value:    "[]",
+			Expected: []interface{}{},
+		},
+		{
+			TestName: "federated",
+			Value:    `[{"userId":"123","providerName":"Google","providerType":"Google","issuer":null,"primary":true,"dateCreated":1612345678901},{"userId":"456","providerName":"Corp","providerType":"SAML","issuer":"urn:corp","primary":"false","dateCreated":1612345678902}]`,
+			Expected: []interface{}{
+				map[string]interface{}{
+					"primary":       true,
+					"provider_name": "Google",
+					"provider_type": "Google",
+					"user_id":       "123",
+				},
+				map[string]interface{}{
+					"primary":       false,
+					"provider_name": "Corp",
+					"provider_type": "SAML",
+					"user_id":       "456",
+				},
+			},
+		},
+		{
+			TestName:    "invalid JSON",
+			Value:       "[{",
+			ExpectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tfcognitoidp.FlattenUserIdentities(testCase.Value)
+
+			if gotErr, want := err != nil, testCase.ExpectError; gotErr != want {
+				t.Fatalf("got error %v, expected error %t", err, want)
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserSensitiveAttributes(t *testing.T) {
+	t.Parallel()
+
+	keys := []string{"custom:ssn", "dev:dob", "custom:missing"}
+	attributes := map[string]interface{}{"email": "test@example.com", "ssn": "123-45-6789", "dev:custom:dob": "2000-01-01"}
+
+	if got, want := tfcognitoidp.OmitUserSensitiveAttributes(attributes, keys), map[string]interface{}{"email": "test@example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+
+	if len(attributes) != 3 {
+		t.Errorf("got attributes %v, expected them to be unchanged", attributes)
+	}
+
+	rawAttributes := cty.MapVal(map[string]cty.Value{
+		"email":   cty.StringVal("test@example.com"),
+		"ssn":     cty.StringVal("123-45-6789"),
+		"dev:dob": cty.UnknownVal(cty.String),
+	})
+
+	if got, want := tfcognitoidp.ExpandUserSensitiveAttributes(rawAttributes, keys), map[string]interface{}{"ssn": "123-45-6789"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+
+	if got := tfcognitoidp.ExpandUserSensitiveAttributes(cty.NullVal(cty.Map(cty.String)), keys); len(got) != 0 {
+		t.Errorf("got %v, expected no attributes", got)
+	}
+
+	if got := tfcognitoidp.UserSensitiveAttributesHash(nil); got != "" {
+		t.Errorf("got hash %q, expected empty", got)
+	}
+
+	hash := tfcognitoidp.UserSensitiveAttributesHash(map[string]interface{}{"ssn": "123-45-6789"})
+
+	if got := tfcognitoidp.UserSensitiveAttributesHash(map[string]interface{}{"ssn": "987-65-4321"}); got == hash {
+		t.Errorf("got the same hash %q for different values", got)
+	}
+
+	if strings.Contains(hash, "123-45-6789") {
+		t.Errorf("got hash %q containing the value", hash)
+	}
+
+	if got, want := tfcognitoidp.UserSensitiveAttributesRemoved(keys, map[string]interface{}{"ssn": "123-45-6789"}, map[string]interface{}{"dev:custom:dob": "2000-01-01"}), []string{"custom:missing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got removed %v, expected %v", got, want)
+	}
+
+	apiList := []*cognitoidentityprovider.AttributeType{
+		{Name: aws.String("email"), Value: aws.String("test@example.com")},
+		{Name: aws.String("custom:ssn"), Value: aws.String("123-45-6789")},
+	}
+
+	if got := tfcognitoidp.OmitUserAttributes(apiList, keys); len(got) != 1 || aws.StringValue(got[0].Name) != "email" {
+		t.Errorf("got %v, expected only email", got)
+	}
+}
+
+func TestUserAutoVerifiedAttributes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		Changed    map[string]interface{}
+		Configured map[string]interface{}
+		AutoVerify []string
+		Expected   map[string]interface{}
+	}{
+		{
+			TestName:   "no auto verify",
+			Changed:    map[string]interface{}{"email": "test@example.com"},
+			Configured: map[string]interface{}{"email": "test@example.com"},
+			Expected:   map[string]interface{}{},
+		},
+		{
+			TestName:   "email",
+			Changed:    map[string]interface{}{"email": "test@example.com", "phone_number": "+15555550100"},
+			Configured: map[string]interface{}{"email": "test@example.com", "phone_number": "+15555550100"},
+			AutoVerify: []string{"email"},
+			Expected:   map[string]interface{}{"email_verified": "true"},
+		},
+		{
+			TestName:   "unchanged",
+			Changed:    map[string]interface{}{"name": "test"},
+			Configured: map[string]interface{}{"email": "test@example.com", "name": "test"},
+			AutoVerify: []string{"email", "phone_number"},
+			Expected:   map[string]interface{}{},
+		},
+		{
+			TestName:   "verified configured",
+			Changed:    map[string]interface{}{"email": "test@example.com", "phone_number": "+15555550100"},
+			Configured: map[string]interface{}{"email": "test@example.com", "email_verified": "false", "phone_number": "+15555550100"},
+			AutoVerify: []string{"email", "phone_number"},
+			Expected:   map[string]interface{}{"phone_number_verified": "true"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserAutoVerifiedAttributes(testCase.Changed, testCase.Configured, testCase.AutoVerify)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserAttributesFromConfig(t *testing.T) {
+	t.Parallel()
+
+	blocks := schema.NewSet(schema.HashResource(tfcognitoidp.ResourceUser().Schema["attribute"].Elem.(*schema.Resource)), []interface{}{
+		map[string]interface{}{"name": "email", "value": "test@example.com"},
+		map[string]interface{}{"name": "custom:foo", "value": "bar"},
+	})
+
+	testCases := []struct {
+		TestName   string
+		Attributes map[string]interface{}
+		Blocks     *schema.Set
+		Expected   map[string]interface{}
+	}{
+		{
+			TestName:   "map",
+			Attributes: map[string]interface{}{"email": "test@example.com"},
+			Blocks:     schema.NewSet(schema.HashString, nil),
+			Expected:   map[string]interface{}{"email": "test@example.com"},
+		},
+		{
+			TestName: "blocks",
+			Blocks:   blocks,
+			Expected: map[string]interface{}{"email": "test@example.com", "custom:foo": "bar"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserAttributesFromConfig(testCase.Attributes, testCase.Blocks)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestFlattenUserAttributeBlocks(t *testing.T) {
+	t.Parallel()
+
+	attributes := map[string]interface{}{
+		"email": "test@example.com",
+		"foo":   "bar",
+		"sub":   "7f3a1d0e-0000-4000-8000-000000000001",
+	}
+	configured := map[string]interface{}{
+		"custom:foo": "baz",
+		"name":       "test",
+	}
+
+	got := tfcognitoidp.FlattenUserAttributeBlocks(attributes, configured)
+	expected := []interface{}{
+		map[string]interface{}{"name": "custom:foo", "value": "bar"},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestApplyUserVerifiedAttributes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName           string
+		Attributes         map[string]interface{}
+		Verified           map[string]bool
+		Expected           map[string]interface{}
+		ExpectedOverridden []string
+	}{
+		{
+			TestName:   "none configured",
+			Attributes: map[string]interface{}{"email": "test@example.com"},
+			Expected:   map[string]interface{}{"email": "test@example.com"},
+		},
+		{
+			TestName:   "email verified",
+			Attributes: map[string]interface{}{"email": "test@example.com"},
+			Verified:   map[string]bool{"email_verified": true},
+			Expected: map[string]interface{}{
+				"email":          "test@example.com",
+				"email_verified": "true",
+			},
+		},
+		{
+			TestName:   "phone number not verified",
+			Attributes: map[string]interface{}{"phone_number": "+15555550100"},
+			Verified:   map[string]bool{"phone_number_verified": false},
+			Expected: map[string]interface{}{
+				"phone_number":          "+15555550100",
+				"phone_number_verified": "false",
+			},
+		},
+		{
+			TestName: "conflicts with attributes",
+			Attributes: map[string]interface{}{
+				"email_verified":        "false",
+				"phone_number_verified": "true",
+			},
+			Verified: map[string]bool{
+				"email_verified":        true,
+				"phone_number_verified": false,
+			},
+			Expected: map[string]interface{}{
+				"email_verified":        "true",
+				"phone_number_verified": "false",
+			},
+			ExpectedOverridden: []string{"email_verified", "phone_number_verified"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			overridden := tfcognitoidp.ApplyUserVerifiedAttributes(testCase.Attributes, testCase.Verified)
+
+			if !reflect.DeepEqual(overridden, testCase.ExpectedOverridden) {
+				t.Errorf("got overridden %v, expected %v", overridden, testCase.ExpectedOverridden)
+			}
+
+			if !reflect.DeepEqual(testCase.Attributes, testCase.Expected) {
+				t.Errorf("got %v, expected %v", testCase.Attributes, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserDefaultDeliveryMediums(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName      string
+		Attributes    map[string]interface{}
+		MessageAction string
+		Expected      []string
+	}{
+		{
+			TestName: "no attributes",
+		},
+		{
+			TestName:   "no email",
+			Attributes: map[string]interface{}{"phone_number": "+15555555555"},
+		},
+		{
+			TestName:   "empty email",
+			Attributes: map[string]interface{}{"email": ""},
+		},
+		{
+			TestName:   "email",
+			Attributes: map[string]interface{}{"email": "test@example.com"},
+			Expected:   []string{cognitoidentityprovider.DeliveryMediumTypeEmail},
+		},
+		{
+			TestName:      "email resend",
+			Attributes:    map[string]interface{}{"email": "test@example.com"},
+			MessageAction: cognitoidentityprovider.MessageActionTypeResend,
+			Expected:      []string{cognitoidentityprovider.DeliveryMediumTypeEmail},
+		},
+		{
+			TestName:      "email suppressed",
+			Attributes:    map[string]interface{}{"email": "test@example.com"},
+			MessageAction: cognitoidentityprovider.MessageActionTypeSuppress,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserDefaultDeliveryMediums(testCase.Attributes, testCase.MessageAction)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserAttributeValuesTooLong(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		Attributes map[string]interface{}
+		Expected   []string
+	}{
+		{
+			TestName: "empty",
+		},
+		{
+			TestName:   "at limit",
+			Attributes: map[string]interface{}{"one": strings.Repeat("a", 2048), "two": strings.Repeat("é", 2048)},
+		},
+		{
+			TestName:   "over limit",
+			Attributes: map[string]interface{}{"one": strings.Repeat("a", 2049), "two": "2", "email": strings.Repeat("a", 3000)},
+			Expected:   []string{"email", "one"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserAttributeValuesTooLong(testCase.Attributes)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestIsImmutableStandardAttribute(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Input    string
+		Expected bool
+	}{
+		{Input: "sub", Expected: true},
+		{Input: "custom:sub", Expected: false},
+		{Input: "email", Expected: false},
+		{Input: "email_verified", Expected: false},
+		{Input: "phone_number_verified", Expected: false},
+		{Input: "updated_at", Expected: false},
+		{Input: "", Expected: false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.Input, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfcognitoidp.IsImmutableStandardAttribute(testCase.Input); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestFlattenUserAttributesRawNames(t *testing.T) {
+	t.Parallel()
+
+	apiList := []*cognitoidentityprovider.AttributeType{
+		{Name: aws.String("email"), Value: aws.String("test@example.com")},
+		{Name: aws.String("custom:one"), Value: aws.String("1")},
+		{Name: aws.String("dev:custom:two"), Value: aws.String("2")},
+	}
+
+	testCases := []struct {
+		TestName string
+		Raw      bool
+		Expected map[string]interface{}
+	}{
+		{
+			TestName: "normalized",
+			Expected: map[string]interface{}{"email": "test@example.com", "one": "1", "dev:two": "2"},
+		},
+		{
+			TestName: "raw",
+			Raw:      true,
+			Expected: map[string]interface{}{"email": "test@example.com", "custom:one": "1", "dev:custom:two": "2"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.FlattenUserAttributes(apiList, testCase.Raw)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+
+			// Expanding the flattened attributes must round-trip to the same names.
+			names := make(map[string]string)
+
+			for _, v := range tfcognitoidp.ExpandAttribute(got, testCase.Raw) {
+				names[aws.StringValue(v.Name)] = aws.StringValue(v.Value)
+			}
+
+			for _, v := range apiList {
+				if got, want := names[aws.StringValue(v.Name)], aws.StringValue(v.Value); got != want {
+					t.Errorf("got %q for %s after expanding, expected %q", got, aws.StringValue(v.Name), want)
+				}
+			}
+		})
+	}
+}
+
+// stubUserConn points the user resource at a local endpoint that answers each Cognito IDP operation with the handler's response.
+// The returned function restores the provider's connection.
+func stubUserConn(t *testing.T, handler func(operation string) (int, string)) func() {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AWSCognitoIdentityProviderService.")
+		status, body := handler(operation)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		Endpoint:    aws.String(server.URL),
+		MaxRetries:  aws.Int(0),
+		Region:      aws.String("us-west-2"),
+	}))
+	conn := cognitoidentityprovider.New(sess)
+
+	restore := tfcognitoidp.StubUserConn(func(interface{}) *cognitoidentityprovider.CognitoIdentityProvider {
+		return conn
+	})
+
+	return func() {
+		restore()
+		server.Close()
+	}
+}
+
+func TestResourceUserDelete(t *testing.T) {
+	userNotFound := `{"__type":"UserNotFoundException","message":"User does not exist."}`
+
+	testCases := []struct {
+		TestName      string
+		DeleteStatus  int
+		DeleteBody    string
+		ExpectedError bool
+		ExpectedCalls []string
+	}{
+		{
+			TestName:      "deleted",
+			DeleteStatus:  http.StatusOK,
+			DeleteBody:    `{}`,
+			ExpectedCalls: []string{"AdminDeleteUser", "AdminGetUser"},
+		},
+		{
+			TestName:      "already deleted",
+			DeleteStatus:  http.StatusBadRequest,
+			DeleteBody:    userNotFound,
+			ExpectedCalls: []string{"AdminDeleteUser"},
+		},
+		{
+			TestName:      "user pool deleted",
+			DeleteStatus:  http.StatusBadRequest,
+			DeleteBody:    `{"__type":"ResourceNotFoundException","message":"User pool us-west-2_test does not exist."}`,
+			ExpectedCalls: []string{"AdminDeleteUser"},
+		},
+		{
+			TestName:      "error",
+			DeleteStatus:  http.StatusBadRequest,
+			DeleteBody:    `{"__type":"NotAuthorizedException","message":"Access denied."}`,
+			ExpectedError: true,
+			ExpectedCalls: []string{"AdminDeleteUser"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			var calls []string
+
+			restore := stubUserConn(t, func(operation string) (int, string) {
+				calls = append(calls, operation)
+
+				if operation == "AdminDeleteUser" {
+					return testCase.DeleteStatus, testCase.DeleteBody
+				}
+
+				return http.StatusBadRequest, userNotFound
+			})
+			defer restore()
+
+			r := tfcognitoidp.ResourceUser()
+			d := r.TestResourceData()
+			d.SetId("us-west-2_test/user")
+			d.Set("user_pool_id", "us-west-2_test")
+			d.Set("username", "user")
+
+			diags := r.DeleteWithoutTimeout(context.Background(), d, nil)
+
+			if got := diags.HasError(); got != testCase.ExpectedError {
+				t.Errorf("got error %t, expected %t: %v", got, testCase.ExpectedError, diags)
+			}
+
+			if !reflect.DeepEqual(calls, testCase.ExpectedCalls) {
+				t.Errorf("got calls %v, expected %v", calls, testCase.ExpectedCalls)
+			}
+		})
+	}
+}
+
+func TestUserMessageSuppressedWithoutPassword(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName             string
+		MessageAction        string
+		HasPassword          bool
+		HasTemporaryPassword bool
+		Expected             bool
+	}{
+		{
+			TestName: "default",
+		},
+		{
+			TestName:      "resend",
+			MessageAction: cognitoidentityprovider.MessageActionTypeResend,
+		},
+		{
+			TestName:      "suppress",
+			MessageAction: cognitoidentityprovider.MessageActionTypeSuppress,
+			Expected:      true,
+		},
+		{
+			TestName:      "suppress password",
+			MessageAction: cognitoidentityprovider.MessageActionTypeSuppress,
+			HasPassword:   true,
+		},
+		{
+			TestName:             "suppress temporary password",
+			MessageAction:        cognitoidentityprovider.MessageActionTypeSuppress,
+			HasTemporaryPassword: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserMessageSuppressedWithoutPassword(testCase.MessageAction, testCase.HasPassword, testCase.HasTemporaryPassword)
+
+			if got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserAliasExistsError(t *testing.T) {
+	t.Parallel()
+
+	upd := map[string]interface{}{"email": "test@example.com", "one": "1"}
+
+	other := errors.New("other")
+
+	if got := tfcognitoidp.UserAliasExistsError(other, upd); got != other {
+		t.Errorf("got %v, expected the error unchanged", got)
+	}
+
+	aliasExists := awserr.New(cognitoidentityprovider.ErrCodeAliasExistsException, "An account with the given email already exists.", nil)
+	got := tfcognitoidp.UserAliasExistsError(aliasExists, upd)
+
+	if !errors.Is(got, aliasExists) {
+		t.Errorf("got %v, expected it to wrap %v", got, aliasExists)
+	}
+
+	for _, want := range []string{"(email)", "force_alias_creation"} {
+		if !strings.Contains(got.Error(), want) {
+			t.Errorf("got %q, expected it to contain %q", got, want)
+		}
+	}
+}
+
+func TestInvalidateUserCache(t *testing.T) {
+	t.Parallel()
+
+	cached := []string{
+		tfcognitoidp.UserCacheKey("us-west-2_invalidate", "user1"),
+		tfcognitoidp.UserCacheKey("us-west-2_invalidate", "user2"),
+		tfcognitoidp.UserCacheKey("us-west-2_invalidateOther", "user1"),
+	}
+
+	for _, key := range cached {
+		tfcognitoidp.UserCache.Store(key, &cognitoidentityprovider.AdminGetUserOutput{})
+	}
+
+	tfcognitoidp.InvalidateUserCache("us-west-2_invalidate")
+
+	for _, key := range cached[:2] {
+		if _, ok := tfcognitoidp.UserCache.Load(key); ok {
+			t.Errorf("expected %s to be invalidated", key)
+		}
+	}
+
+	if _, ok := tfcognitoidp.UserCache.Load(cached[2]); !ok {
+		t.Errorf("expected %s to remain cached", cached[2])
+	}
+
+	tfcognitoidp.InvalidateUserCache("us-west-2_invalidateOther")
+}
+
+func TestUserAttributeKeyCollisions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		Attributes map[string]interface{}
+		Expected   []string
+	}{
+		{
+			TestName: "empty",
+		},
+		{
+			TestName:   "no collision",
+			Attributes: map[string]interface{}{"email": "test@example.com", "foo": "1", "custom:bar": "2"},
+		},
+		{
+			TestName:   "prefix collision",
+			Attributes: map[string]interface{}{"email": "test@example.com", "foo": "1", "custom:foo": "2"},
+			Expected:   []string{"custom:foo (custom:foo, foo)"},
+		},
+		{
+			TestName:   "multiple collisions",
+			Attributes: map[string]interface{}{"foo": "1", "custom:foo": "2", "dev:bar": "3", "dev:custom:bar": "4"},
+			Expected:   []string{"custom:foo (custom:foo, foo)", "dev:custom:bar (dev:bar, dev:custom:bar)"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserAttributeKeyCollisions(testCase.Attributes)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserImmutableAttributeKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		Attributes map[string]interface{}
+		Expected   []string
+	}{
+		{
+			TestName: "empty",
+		},
+		{
+			TestName:   "mutable",
+			Attributes: map[string]interface{}{"email": "test@example.com", "email_verified": "true", "sub_id": "1"},
+		},
+		{
+			TestName:   "sub",
+			Attributes: map[string]interface{}{"email": "test@example.com", "sub": "11111111-2222-3333-4444-555555555555"},
+			Expected:   []string{"sub"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfcognitoidp.UserImmutableAttributeKeys(testCase.Attributes)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestExpandUserPassword(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName          string
+		Password          string
+		TemporaryPassword string
+		Permanent         *bool
+		ExpectedPassword  string
+		ExpectedPermanent bool
+	}{
+		{
+			TestName:          "none",
+			ExpectedPermanent: true,
+		},
+		{
+			TestName:          "password",
+			Password:          "Passw0rd!",
+			ExpectedPassword:  "Passw0rd!",
+			ExpectedPermanent: true,
+		},
+		{
+			TestName:          "password permanent",
+			Password:          "Passw0rd!",
+			Permanent:         aws.Bool(true),
+			ExpectedPassword:  "Passw0rd!",
+			ExpectedPermanent: true,
+		},
+		{
+			TestName:         "password not permanent",
+			Password:         "Passw0rd!",
+			Permanent:        aws.Bool(false),
+			ExpectedPassword: "Passw0rd!",
+		},
+		{
+			TestName:          "temporary password",
+			TemporaryPassword: "Temp0rary!",
+			ExpectedPassword:  "Temp0rary!",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			password, permanent := tfcognitoidp.ExpandUserPassword(testCase.Password, testCase.TemporaryPassword, testCase.Permanent)
+
+			if password != testCase.ExpectedPassword {
+				t.Errorf("got password %q, expected %q", password, testCase.ExpectedPassword)
+			}
+
+			if permanent != testCase.ExpectedPermanent {
+				t.Errorf("got permanent %t, expected %t", permanent, testCase.ExpectedPermanent)
+			}
+		})
+	}
+}
+
+func TestUserPasswordResetRequired(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Status   string
+		Expected bool
+	}{
+		{Status: cognitoidentityprovider.UserStatusTypeConfirmed},
+		{Status: cognitoidentityprovider.UserStatusTypeForceChangePassword, Expected: true},
+		{Status: cognitoidentityprovider.UserStatusTypeResetRequired, Expected: true},
+		{Status: cognitoidentityprovider.UserStatusTypeUnconfirmed},
+		{Status: cognitoidentityprovider.UserStatusTypeCompromised},
+		{Status: ""},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.Status, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfcognitoidp.UserPasswordResetRequired(testCase.Status); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestUserSMSMFAPhoneNumberError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName    string
+		Attributes  []*cognitoidentityprovider.AttributeType
+		ExpectError string
+	}{
+		{
+			TestName:    "no phone number",
+			Attributes:  []*cognitoidentityprovider.AttributeType{{Name: aws.String("email"), Value: aws.String("test@example.com")}},
+			ExpectError: "requires the user to have a phone_number attribute",
+		},
+		{
+			TestName:    "unverified phone number",
+			Attributes:  []*cognitoidentityprovider.AttributeType{{Name: aws.String("phone_number"), Value: aws.String("+15555550100")}},
+			ExpectError: "requires the user's phone_number to be verified",
+		},
+		{
+			TestName: "phone number verified false",
+			Attributes: []*cognitoidentityprovider.AttributeType{
+				{Name: aws.String("phone_number"), Value: aws.String("+15555550100")},
+				{Name: aws.String("phone_number_verified"), Value: aws.String("false")},
+			},
+			ExpectError: "requires the user's phone_number to be verified",
+		},
+		{
+			TestName: "verified phone number",
+			Attributes: []*cognitoidentityprovider.AttributeType{
+				{Name: aws.String("phone_number"), Value: aws.String("+15555550100")},
+				{Name: aws.String("phone_number_verified"), Value: aws.String("true")},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			err := tfcognitoidp.UserSMSMFAPhoneNumberError(testCase.Attributes)
+
+			if testCase.ExpectError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), testCase.ExpectError) {
+				t.Errorf("got error %v, expected %q", err, testCase.ExpectError)
+			}
+		})
+	}
+}
+
+func TestUserMFAOptions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName   string
+		TFList     []interface{}
+		APIObjects []*cognitoidentityprovider.MFAOptionType
+	}{
+		{
+			TestName:   "empty",
+			TFList:     []interface{}{},
+			APIObjects: []*cognitoidentityprovider.MFAOptionType{},
+		},
+		{
+			TestName: "sms",
+			TFList: []interface{}{
+				map[string]interface{}{"attribute_name": "phone_number", "delivery_medium": cognitoidentityprovider.DeliveryMediumTypeSms},
+			},
+			APIObjects: []*cognitoidentityprovider.MFAOptionType{
+				{AttributeName: aws.String("phone_number"), DeliveryMedium: aws.String(cognitoidentityprovider.DeliveryMediumTypeSms)},
+			},
+		},
+		{
+			TestName: "no attribute name",
+			TFList: []interface{}{
+				map[string]interface{}{"attribute_name": "", "delivery_medium": cognitoidentityprovider.DeliveryMediumTypeEmail},
+			},
+			APIObjects: []*cognitoidentityprovider.MFAOptionType{
+				{DeliveryMedium: aws.String(cognitoidentityprovider.DeliveryMediumTypeEmail)},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfcognitoidp.ExpandUserMFAOptions(testCase.TFList); !reflect.DeepEqual(got, testCase.APIObjects) {
+				t.Errorf("expand: got %v, expected %v", got, testCase.APIObjects)
+			}
+
+			if got := tfcognitoidp.FlattenUserMFAOptions(testCase.APIObjects); !reflect.DeepEqual(got, testCase.TFList) {
+				t.Errorf("flatten: got %v, expected %v", got, testCase.TFList)
+			}
+		})
+	}
+}
+
+func TestUserMFAFallbackOrder(t *testing.T) {
+	t.Parallel()
+
+	both := []string{cognitoidentityprovider.ChallengeNameTypeSmsMfa, cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa}
+
+	testCases := []struct {
+		TestName    string
+		SettingList []string
+		Preferred   string
+		Expected    []string
+	}{
+		{
+			TestName: "none",
+			Expected: []string{},
+		},
+		{
+			TestName:    "both no preferred",
+			SettingList: both,
+			Expected:    both,
+		},
+		{
+			TestName:    "both sms preferred",
+			SettingList: both,
+			Preferred:   cognitoidentityprovider.ChallengeNameTypeSmsMfa,
+			Expected:    both,
+		},
+		{
+			TestName:    "both software token preferred",
+			SettingList: both,
+			Preferred:   cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa,
+			Expected:    []string{cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa, cognitoidentityprovider.ChallengeNameTypeSmsMfa},
+		},
+		{
+			TestName:    "preferred not activated",
+			SettingList: []string{cognitoidentityprovider.ChallengeNameTypeSmsMfa},
+			Preferred:   cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa,
+			Expected:    []string{cognitoidentityprovider.ChallengeNameTypeSmsMfa},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			var preferred *string
+			if testCase.Preferred != "" {
+				preferred = aws.String(testCase.Preferred)
+			}
+
+			got := tfcognitoidp.UserMFAFallbackOrder(aws.StringSlice(testCase.SettingList), preferred)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestPartitionUserAttributes(t *testing.T) {
+	t.Parallel()
+
+	standard, custom := tfcognitoidp.PartitionUserAttributes(map[string]interface{}{
+		"email":          "test@example.com",
+		"email_verified": "true",
+		"sub":            "7f3a1d0e-0000-4000-8000-000000000001",
+		"one":            "1",
+		"department":     "engineering",
+	})
+
+	if want := map[string]interface{}{"email": "test@example.com", "email_verified": "true", "sub": "7f3a1d0e-0000-4000-8000-000000000001"}; !reflect.DeepEqual(standard, want) {
+		t.Errorf("standard attributes: got %v, expected %v", standard, want)
+	}
+
+	if want := map[string]interface{}{"one": "1", "department": "engineering"}; !reflect.DeepEqual(custom, want) {
+		t.Errorf("custom attributes: got %v, expected %v", custom, want)
+	}
+}
+
+func TestUserIdentityHash(t *testing.T) {
+	t.Parallel()
+
+	hash := tfcognitoidp.UserIdentityHash("us-west-2_aaaaaaaaa", "7f3a1d0e-0000-4000-8000-000000000001")
+
+	if got := tfcognitoidp.UserIdentityHash("us-west-2_aaaaaaaaa", "7f3a1d0e-0000-4000-8000-000000000001"); got != hash {
+		t.Errorf("hash is not stable: got %q, expected %q", got, hash)
+	}
+
+	if got := tfcognitoidp.UserIdentityHash("us-west-2_aaaaaaaaa", "7f3a1d0e-0000-4000-8000-000000000002"); got == hash {
+		t.Errorf("hash for a different user must differ: %q", got)
+	}
+
+	if got := tfcognitoidp.UserIdentityHash("us-west-2_bbbbbbbbb", "7f3a1d0e-0000-4000-8000-000000000001"); got == hash {
+		t.Errorf("hash for a different user pool must differ: %q", got)
+	}
+
+	if got, want := len(hash), 64; got != want {
+		t.Errorf("hash length: got %d, expected %d", got, want)
+	}
+}
+
+func testAccCheckUserInGroup(ctx context.Context, n, groupName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+
+		found, err := tfcognitoidp.FindCognitoUserInGroup(ctx, conn, groupName, rs.Primary.Attributes["user_pool_id"], rs.Primary.Attributes["username"])
+
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return fmt.Errorf("Cognito User %s is not a member of group %s", rs.Primary.ID, groupName)
+		}
+
+		return nil
+	}
+}
+
+func testAccUpdateUserAttribute(ctx context.Context, n, name, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+
+		_, err := conn.AdminUpdateUserAttributesWithContext(ctx, &cognitoidentityprovider.AdminUpdateUserAttributesInput{
+			UserAttributes: []*cognitoidentityprovider.AttributeType{{
+				Name:  aws.String(name),
+				Value: aws.String(value),
+			}},
+			UserPoolId: aws.String(rs.Primary.Attributes["user_pool_id"]),
+			Username:   aws.String(rs.Primary.Attributes["username"]),
+		})
+
+		return err
+	}
+}
+
+func testAccUserImportStateIdFunc(resourceName, separator string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("Not found: %s", resourceName)
+		}
+
+		return rs.Primary.Attributes["user_pool_id"] + separator + rs.Primary.Attributes["username"], nil
+	}
+}
+
+func testAccCheckUserEnabled(ctx context.Context, n string, enabled bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+
+		user, err := tfcognitoidp.FindUserByTwoPartKey(ctx, conn, rs.Primary.Attributes["user_pool_id"], rs.Primary.Attributes["username"])
+
+		if err != nil {
+			return err
+		}
+
+		if got := aws.BoolValue(user.Enabled); got != enabled {
+			return fmt.Errorf("Cognito User (%s) enabled is %t, expected %t", rs.Primary.ID, got, enabled)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckUserExists(ctx context.Context, n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No Cognito User ID is set")
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+
+		_, err := tfcognitoidp.FindUserByTwoPartKey(ctx, conn, rs.Primary.Attributes["user_pool_id"], rs.Primary.Attributes["username"])
+
+		return err
+	}
+}
+
+// testAccCheckUserCreateOutOfBand creates a user in the user pool without Terraform.
+func testAccCheckUserCreateOutOfBand(ctx context.Context, n, username string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+
+		_, err := conn.AdminCreateUserWithContext(ctx, &cognitoidentityprovider.AdminCreateUserInput{
+			MessageAction: aws.String(cognitoidentityprovider.MessageActionTypeSuppress),
+			UserAttributes: []*cognitoidentityprovider.AttributeType{
+				{
+					Name:  aws.String("email"),
+					Value: aws.String("test@example.com"),
+				},
+			},
+			UserPoolId: aws.String(rs.Primary.ID),
+			Username:   aws.String(username),
+		})
+
+		return err
+	}
+}
+
+func testAccCheckUserDestroy(ctx context.Context) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "aws_cognito_user" {
+				continue
+			}
+
+			_, err := tfcognitoidp.FindUserByTwoPartKey(ctx, conn, rs.Primary.Attributes["user_pool_id"], rs.Primary.Attributes["username"])
+
+			if tfresource.NotFound(err) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
 
 			return fmt.Errorf("Cognito User %s still exists", rs.Primary.ID)
 		}
 
-		return nil
-	}
+		return nil
+	}
+}
+
+func testAccUserTemporaryPassword(ctx context.Context, userResName string, clientResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		userRs, ok := s.RootModule().Resources[userResName]
+		if !ok {
+			return fmt.Errorf("Not found: %s", userResName)
+		}
+
+		clientRs, ok := s.RootModule().Resources[clientResName]
+		if !ok {
+			return fmt.Errorf("Not found: %s", clientResName)
+		}
+
+		userName := userRs.Primary.Attributes["username"]
+		userPassword := userRs.Primary.Attributes["temporary_password"]
+		clientId := clientRs.Primary.Attributes["id"]
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+
+		params := &cognitoidentityprovider.InitiateAuthInput{
+			AuthFlow: aws.String(cognitoidentityprovider.AuthFlowTypeUserPasswordAuth),
+			AuthParameters: map[string]*string{
+				"USERNAME": aws.String(userName),
+				"PASSWORD": aws.String(userPassword),
+			},
+			ClientId: aws.String(clientId),
+		}
+
+		resp, err := conn.InitiateAuthWithContext(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		if aws.StringValue(resp.ChallengeName) != cognitoidentityprovider.ChallengeNameTypeNewPasswordRequired {
+			return errors.New("The password is not a temporary password.")
+		}
+
+		return nil
+	}
+}
+
+func testAccUserPassword(ctx context.Context, userResName string, clientResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		userRs, ok := s.RootModule().Resources[userResName]
+		if !ok {
+			return fmt.Errorf("Not found: %s", userResName)
+		}
+
+		clientRs, ok := s.RootModule().Resources[clientResName]
+		if !ok {
+			return fmt.Errorf("Not found: %s", clientResName)
+		}
+
+		userName := userRs.Primary.Attributes["username"]
+		userPassword := userRs.Primary.Attributes["password"]
+		clientId := clientRs.Primary.Attributes["id"]
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+
+		params := &cognitoidentityprovider.InitiateAuthInput{
+			AuthFlow: aws.String(cognitoidentityprovider.AuthFlowTypeUserPasswordAuth),
+			AuthParameters: map[string]*string{
+				"USERNAME": aws.String(userName),
+				"PASSWORD": aws.String(userPassword),
+			},
+			ClientId: aws.String(clientId),
+		}
+
+		resp, err := conn.InitiateAuthWithContext(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		if resp.AuthenticationResult == nil {
+			return errors.New("Authentication has failed.")
+		}
+
+		return nil
+	}
+}
+
+func testAccUserConfig_basic(userPoolName string, userName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[2]q
+}
+`, userPoolName, userName)
+}
+
+func testAccUserConfig_temporaryPassword(userPoolName string, clientName string, userName string, password string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+  password_policy {
+    temporary_password_validity_days = 7
+    minimum_length                   = 6
+    require_uppercase                = false
+    require_symbols                  = false
+    require_numbers                  = false
+  }
+}
+
+resource "aws_cognito_user_pool_client" "test" {
+  name                = %[2]q
+  user_pool_id        = aws_cognito_user_pool.test.id
+  explicit_auth_flows = ["ALLOW_USER_PASSWORD_AUTH", "ALLOW_REFRESH_TOKEN_AUTH"]
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id       = aws_cognito_user_pool.test.id
+  username           = %[3]q
+  temporary_password = %[4]q
+}
+`, userPoolName, clientName, userName, password)
+}
+
+func testAccUserConfig_passwordPermanent(userPoolName, clientName, userName, password string, permanent bool) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+  password_policy {
+    temporary_password_validity_days = 7
+    minimum_length                   = 6
+    require_uppercase                = false
+    require_symbols                  = false
+    require_numbers                  = false
+  }
+}
+
+resource "aws_cognito_user_pool_client" "test" {
+  name                = %[2]q
+  user_pool_id        = aws_cognito_user_pool.test.id
+  explicit_auth_flows = ["ALLOW_USER_PASSWORD_AUTH", "ALLOW_REFRESH_TOKEN_AUTH"]
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id       = aws_cognito_user_pool.test.id
+  username           = %[3]q
+  password           = %[4]q
+  password_permanent = %[5]t
+}
+`, userPoolName, clientName, userName, password, permanent)
+}
+
+func testAccUserConfig_password(userPoolName string, clientName string, userName string, password string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+  password_policy {
+    temporary_password_validity_days = 7
+    minimum_length                   = 6
+    require_uppercase                = false
+    require_symbols                  = false
+    require_numbers                  = false
+  }
+}
+
+resource "aws_cognito_user_pool_client" "test" {
+  name                = %[2]q
+  user_pool_id        = aws_cognito_user_pool.test.id
+  explicit_auth_flows = ["ALLOW_USER_PASSWORD_AUTH", "ALLOW_REFRESH_TOKEN_AUTH"]
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[3]q
+  password     = %[4]q
+}
+`, userPoolName, clientName, userName, password)
+}
+
+func testAccUserConfig_noPassword(userPoolName string, clientName string, userName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+  password_policy {
+    temporary_password_validity_days = 7
+    minimum_length                   = 6
+    require_uppercase                = false
+    require_symbols                  = false
+    require_numbers                  = false
+  }
+}
+
+resource "aws_cognito_user_pool_client" "test" {
+  name                = %[2]q
+  user_pool_id        = aws_cognito_user_pool.test.id
+  explicit_auth_flows = ["ALLOW_USER_PASSWORD_AUTH", "ALLOW_REFRESH_TOKEN_AUTH"]
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[3]q
+}
+`, userPoolName, clientName, userName)
+}
+
+func testAccUserConfig_attributes(userPoolName string, userName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  schema {
+    name                     = "one"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+  schema {
+    name                     = "two"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+  schema {
+    name                     = "three"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+  schema {
+    name                     = "four"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[2]q
+
+  attributes = {
+    one   = "1"
+    two   = "2"
+    three = "3"
+  }
+}
+`, userPoolName, userName)
+}
+
+func testAccUserConfig_attributesUpdated(userPoolName string, userName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  schema {
+    name                     = "one"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+  schema {
+    name                     = "two"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+  schema {
+    name                     = "three"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+  schema {
+    name                     = "four"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[2]q
+
+  attributes = {
+    two   = "2"
+    three = "three"
+    four  = "4"
+  }
+}
+`, userPoolName, userName)
+}
+
+func testAccUserConfig_enable(userPoolName string, userName string, enabled bool) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[2]q
+  enabled      = %[3]t
+}
+`, userPoolName, userName, enabled)
+}
+
+func testAccUserConfig_attributesDocument(userPoolName, userName, document string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  schema {
+    name                     = "one"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+  schema {
+    name                     = "two"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id        = aws_cognito_user_pool.test.id
+  username            = %[2]q
+  attributes_document = %[3]q
+
+  attributes = {
+    two = "two"
+  }
+}
+`, userPoolName, userName, document)
 }
 
-func testAccUserTemporaryPassword(ctx context.Context, userResName string, clientResName string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		userRs, ok := s.RootModule().Resources[userResName]
-		if !ok {
-			return fmt.Errorf("Not found: %s", userResName)
-		}
+func testAccUserConfig_attributesJSON(userPoolName, userName, document string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
 
-		clientRs, ok := s.RootModule().Resources[clientResName]
-		if !ok {
-			return fmt.Errorf("Not found: %s", clientResName)
-		}
+  schema {
+    name                     = "one"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+  schema {
+    name                     = "two"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id        = aws_cognito_user_pool.test.id
+  username            = %[2]q
+  attributes_json     = %[3]q
+
+  attributes = {
+    two = "two"
+  }
+}
+`, userPoolName, userName, document)
+}
+
+func testAccUserConfig_rawAttributeNames(userPoolName, userName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  schema {
+    name                     = "one"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id        = aws_cognito_user_pool.test.id
+  username            = %[2]q
+  raw_attribute_names = true
+
+  attributes = {
+    "custom:one" = "1"
+    email        = "test@example.com"
+  }
+}
+`, userPoolName, userName)
+}
+
+func testAccUserConfig_defaultGroups(userPoolName, userName, groupName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+
+resource "aws_cognito_user_group" "test" {
+  count = 2
+
+  name         = "%[3]s-${count.index + 1}"
+  user_pool_id = aws_cognito_user_pool.test.id
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id   = aws_cognito_user_pool.test.id
+  username       = %[2]q
+  default_groups = aws_cognito_user_group.test[*].name
+}
+`, userPoolName, userName, groupName)
+}
+
+func testAccUserConfig_waitForAttributePropagation(userPoolName, userName, value string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  schema {
+    name                     = "one"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id                   = aws_cognito_user_pool.test.id
+  username                       = %[2]q
+  wait_for_attribute_propagation = true
+
+  attributes = {
+    one = %[3]q
+  }
+}
+`, userPoolName, userName, value)
+}
+
+func testAccUserConfig_attributeMergeStrategy(userPoolName, userName, strategy, value string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  schema {
+    name                     = "one"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id             = aws_cognito_user_pool.test.id
+  username                 = %[2]q
+  attribute_merge_strategy = %[3]q
+
+  attributes = {
+    one = %[4]q
+  }
+}
+`, userPoolName, userName, strategy, value)
+}
+
+func testAccUserConfig_allowedAttributeKeys(userPoolName, userName, allowed string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  schema {
+    name                     = "one"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+  schema {
+    name                     = "two"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id           = aws_cognito_user_pool.test.id
+  username               = %[2]q
+  allowed_attribute_keys = %[3]s
+
+  attributes = {
+    one = "1"
+    two = "2"
+  }
+}
+`, userPoolName, userName, allowed)
+}
+
+func testAccUserConfig_desiredStatus(userPoolName, userName, email, passwordArgument, password, desiredStatus string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  password_policy {
+    temporary_password_validity_days = 7
+    minimum_length                   = 6
+    require_uppercase                = false
+    require_symbols                  = false
+    require_numbers                  = false
+  }
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id   = aws_cognito_user_pool.test.id
+  username       = %[2]q
+  %[4]s = %[5]q
+  desired_status = %[6]q
+
+  attributes = {
+    email          = %[3]q
+    email_verified = "true"
+  }
+}
+`, userPoolName, userName, email, passwordArgument, password, desiredStatus)
+}
+
+func testAccUserConfig_groups(userPoolName, userName, groupName, groupIndexes string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+
+resource "aws_cognito_user_group" "test" {
+  count = 3
+
+  name         = "%[3]s-${count.index}"
+  user_pool_id = aws_cognito_user_pool.test.id
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[2]q
+  groups       = [for i in %[4]s : aws_cognito_user_group.test[i].name]
+}
+`, userPoolName, userName, groupName, groupIndexes)
+}
+
+func testAccUserConfig_groupsNotFound(userPoolName, userName, groupName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[2]q
+  groups       = [%[3]q]
+}
+`, userPoolName, userName, groupName)
+}
+
+func testAccUserConfig_mfaSettings(userPoolName, userName string, enabled, preferred bool) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[2]q
+
+  attributes = {
+    phone_number = "+15555550100"
+  }
+
+  phone_number_verified = true
+
+  sms_mfa_settings {
+    enabled   = %[3]t
+    preferred = %[4]t
+  }
+}
+`, userPoolName, userName, enabled, preferred)
+}
+
+func testAccUserConfig_forcePasswordReset(userPoolName, userName, email string, forcePasswordReset bool) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id         = aws_cognito_user_pool.test.id
+  username             = %[2]q
+  password             = "Password1!"
+  force_password_reset = %[4]t
+
+  attributes = {
+    email          = %[3]q
+    email_verified = "true"
+  }
+}
+`, userPoolName, userName, email, forcePasswordReset)
+}
+
+func testAccUserConfig_attributesNotInSchema(userPoolName, userName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  schema {
+    name                     = "two"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = false
+    string_attribute_constraints {}
+  }
+}
 
-		userName := userRs.Primary.Attributes["username"]
-		userPassword := userRs.Primary.Attributes["temporary_password"]
-		clientId := clientRs.Primary.Attributes["id"]
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[2]q
 
-		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+  attributes = {
+    tow = "2"
+  }
+}
+`, userPoolName, userName)
+}
 
-		params := &cognitoidentityprovider.InitiateAuthInput{
-			AuthFlow: aws.String(cognitoidentityprovider.AuthFlowTypeUserPasswordAuth),
-			AuthParameters: map[string]*string{
-				"USERNAME": aws.String(userName),
-				"PASSWORD": aws.String(userPassword),
-			},
-			ClientId: aws.String(clientId),
-		}
+func testAccUserConfig_developerOnlyAttribute(userPoolName, userName string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
 
-		resp, err := conn.InitiateAuthWithContext(ctx, params)
-		if err != nil {
-			return err
-		}
+  schema {
+    name                     = "secret"
+    attribute_data_type      = "String"
+    mutable                  = true
+    required                 = false
+    developer_only_attribute = true
+    string_attribute_constraints {}
+  }
+}
 
-		if aws.StringValue(resp.ChallengeName) != cognitoidentityprovider.ChallengeNameTypeNewPasswordRequired {
-			return errors.New("The password is not a temporary password.")
-		}
+resource "aws_cognito_user" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = %[2]q
 
-		return nil
-	}
+  attributes = {
+    "dev:secret" = "1"
+  }
+}
+`, userPoolName, userName)
 }
 
-func testAccUserPassword(ctx context.Context, userResName string, clientResName string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		userRs, ok := s.RootModule().Resources[userResName]
-		if !ok {
-			return fmt.Errorf("Not found: %s", userResName)
-		}
+func testAccUserConfig_verifyCreate(rName, emailVerified string) string {
+	return fmt.Sprintf(`
+resource "aws_iam_role" "test" {
+  name = %[1]q
 
-		clientRs, ok := s.RootModule().Resources[clientResName]
-		if !ok {
-			return fmt.Errorf("Not found: %s", clientResName)
-		}
+  assume_role_policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Action": "sts:AssumeRole",
+      "Principal": {
+        "Service": "lambda.amazonaws.com"
+      },
+      "Effect": "Allow",
+      "Sid": ""
+    }
+  ]
+}
+EOF
+}
 
-		userName := userRs.Primary.Attributes["username"]
-		userPassword := userRs.Primary.Attributes["password"]
-		clientId := clientRs.Primary.Attributes["id"]
+resource "aws_lambda_function" "test" {
+  filename      = "test-fixtures/lambda_pre_sign_up_auto_verify.zip"
+  function_name = %[1]q
+  role          = aws_iam_role.test.arn
+  handler       = "index.handler"
+  runtime       = "nodejs16.x"
+}
 
-		conn := acctest.Provider.Meta().(*conns.AWSClient).CognitoIDPConn()
+resource "aws_lambda_permission" "test" {
+  statement_id  = "AllowExecutionFromCognito"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.test.function_name
+  principal     = "cognito-idp.amazonaws.com"
+  source_arn    = aws_cognito_user_pool.test.arn
+}
 
-		params := &cognitoidentityprovider.InitiateAuthInput{
-			AuthFlow: aws.String(cognitoidentityprovider.AuthFlowTypeUserPasswordAuth),
-			AuthParameters: map[string]*string{
-				"USERNAME": aws.String(userName),
-				"PASSWORD": aws.String(userPassword),
-			},
-			ClientId: aws.String(clientId),
-		}
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
 
-		resp, err := conn.InitiateAuthWithContext(ctx, params)
-		if err != nil {
-			return err
-		}
+  lambda_config {
+    pre_sign_up = aws_lambda_function.test.arn
+  }
+}
 
-		if resp.AuthenticationResult == nil {
-			return errors.New("Authentication has failed.")
-		}
+resource "aws_cognito_user" "test" {
+  user_pool_id  = aws_cognito_user_pool.test.id
+  username      = %[1]q
+  verify_create = true
 
-		return nil
-	}
+  attributes = {
+    email          = "test@example.com"
+    email_verified = %[2]q
+  }
+
+  depends_on = [aws_lambda_permission.test]
+}
+`, rName, emailVerified)
 }
 
-func testAccUserConfig_basic(userPoolName string, userName string) string {
+func testAccUserConfig_usernameCaseInsensitive(userPoolName, userName string) string {
 	return fmt.Sprintf(`
 resource "aws_cognito_user_pool" "test" {
   name = %[1]q
+
+  username_configuration {
+    case_sensitive = false
+  }
 }
 
 resource "aws_cognito_user" "test" {
@@ -427,117 +4734,138 @@ resource "aws_cognito_user" "test" {
 `, userPoolName, userName)
 }
 
-func testAccUserConfig_temporaryPassword(userPoolName string, clientName string, userName string, password string) string {
+func testAccUserConfig_attributeApplyOrder(userPoolName, userName, email, emailVerified string) string {
 	return fmt.Sprintf(`
 resource "aws_cognito_user_pool" "test" {
   name = %[1]q
-  password_policy {
-    temporary_password_validity_days = 7
-    minimum_length                   = 6
-    require_uppercase                = false
-    require_symbols                  = false
-    require_numbers                  = false
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id          = aws_cognito_user_pool.test.id
+  username              = %[2]q
+  attribute_apply_order = ["email", "email_verified"]
+
+  attributes = {
+    email          = %[3]q
+    email_verified = %[4]q
   }
 }
+`, userPoolName, userName, email, emailVerified)
+}
 
-resource "aws_cognito_user_pool_client" "test" {
-  name                = %[2]q
-  user_pool_id        = aws_cognito_user_pool.test.id
-  explicit_auth_flows = ["ALLOW_USER_PASSWORD_AUTH", "ALLOW_REFRESH_TOKEN_AUTH"]
+func testAccUserConfig_temporaryPasswordPolicy(userPoolName, userName, password string) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+
+  password_policy {
+    minimum_length    = 8
+    require_lowercase = true
+    require_uppercase = true
+    require_symbols   = true
+    require_numbers   = true
+  }
 }
 
 resource "aws_cognito_user" "test" {
   user_pool_id       = aws_cognito_user_pool.test.id
-  username           = %[3]q
-  temporary_password = %[4]q
+  username           = %[2]q
+  temporary_password = %[3]q
 }
-`, userPoolName, clientName, userName, password)
+`, userPoolName, userName, password)
 }
 
-func testAccUserConfig_password(userPoolName string, clientName string, userName string, password string) string {
+func testAccUserConfig_globalSignOut(userPoolName, userName string, enabled bool, globalSignOut string) string {
 	return fmt.Sprintf(`
 resource "aws_cognito_user_pool" "test" {
   name = %[1]q
-  password_policy {
-    temporary_password_validity_days = 7
-    minimum_length                   = 6
-    require_uppercase                = false
-    require_symbols                  = false
-    require_numbers                  = false
-  }
 }
 
-resource "aws_cognito_user_pool_client" "test" {
-  name                = %[2]q
-  user_pool_id        = aws_cognito_user_pool.test.id
-  explicit_auth_flows = ["ALLOW_USER_PASSWORD_AUTH", "ALLOW_REFRESH_TOKEN_AUTH"]
+resource "aws_cognito_user" "test" {
+  user_pool_id    = aws_cognito_user_pool.test.id
+  username        = %[2]q
+  enabled         = %[3]t
+  global_sign_out = %[4]q
 }
+`, userPoolName, userName, enabled, globalSignOut)
+}
+
+func testAccUserConfig_resendExisting(userPoolName, userName string, includeUser bool) string {
+	config := fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+`, userPoolName)
+
+	if !includeUser {
+		return config
+	}
 
+	return acctest.ConfigCompose(config, fmt.Sprintf(`
 resource "aws_cognito_user" "test" {
-  user_pool_id = aws_cognito_user_pool.test.id
-  username     = %[3]q
-  password     = %[4]q
+  user_pool_id             = aws_cognito_user_pool.test.id
+  username                 = %[1]q
+  message_action           = "RESEND"
+  desired_delivery_mediums = ["EMAIL"]
+
+  attributes = {
+    email = "test@example.com"
+  }
 }
-`, userPoolName, clientName, userName, password)
+`, userName))
 }
 
-func testAccUserConfig_noPassword(userPoolName string, clientName string, userName string) string {
+func testAccUserConfig_verifiedAttributes(userPoolName, userName string, emailVerified, phoneNumberVerified bool) string {
 	return fmt.Sprintf(`
 resource "aws_cognito_user_pool" "test" {
   name = %[1]q
-  password_policy {
-    temporary_password_validity_days = 7
-    minimum_length                   = 6
-    require_uppercase                = false
-    require_symbols                  = false
-    require_numbers                  = false
+}
+
+resource "aws_cognito_user" "test" {
+  user_pool_id          = aws_cognito_user_pool.test.id
+  username              = %[2]q
+  email_verified        = %[3]t
+  phone_number_verified = %[4]t
+
+  attributes = {
+    email        = "test@example.com"
+    phone_number = "+15555550100"
   }
 }
+`, userPoolName, userName, emailVerified, phoneNumberVerified)
+}
 
-resource "aws_cognito_user_pool_client" "test" {
-  name                = %[2]q
-  user_pool_id        = aws_cognito_user_pool.test.id
-  explicit_auth_flows = ["ALLOW_USER_PASSWORD_AUTH", "ALLOW_REFRESH_TOKEN_AUTH"]
+func testAccUserConfig_consistentRead(userPoolName, userName string, includeName bool) string {
+	name := ""
+	if includeName {
+		name = `name  = "test"`
+	}
+
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
 }
 
 resource "aws_cognito_user" "test" {
-  user_pool_id = aws_cognito_user_pool.test.id
-  username     = %[3]q
+  user_pool_id    = aws_cognito_user_pool.test.id
+  username        = %[2]q
+  consistent_read = true
+
+  attributes = {
+    email = "test@example.com"
+    %[3]s
+  }
 }
-`, userPoolName, clientName, userName)
+`, userPoolName, userName, name)
 }
 
-func testAccUserConfig_attributes(userPoolName string, userName string) string {
+func testAccUserConfig_tags(userPoolName, userName, env string) string {
 	return fmt.Sprintf(`
 resource "aws_cognito_user_pool" "test" {
   name = %[1]q
 
   schema {
-    name                     = "one"
-    attribute_data_type      = "String"
-    mutable                  = true
-    required                 = false
-    developer_only_attribute = false
-    string_attribute_constraints {}
-  }
-  schema {
-    name                     = "two"
-    attribute_data_type      = "String"
-    mutable                  = true
-    required                 = false
-    developer_only_attribute = false
-    string_attribute_constraints {}
-  }
-  schema {
-    name                     = "three"
-    attribute_data_type      = "String"
-    mutable                  = true
-    required                 = false
-    developer_only_attribute = false
-    string_attribute_constraints {}
-  }
-  schema {
-    name                     = "four"
+    name                     = "tag_env"
     attribute_data_type      = "String"
     mutable                  = true
     required                 = false
@@ -550,68 +4878,29 @@ resource "aws_cognito_user" "test" {
   user_pool_id = aws_cognito_user_pool.test.id
   username     = %[2]q
 
-  attributes = {
-    one   = "1"
-    two   = "2"
-    three = "3"
+  tags = {
+    env = %[3]q
   }
 }
-`, userPoolName, userName)
+`, userPoolName, userName, env)
 }
 
-func testAccUserConfig_attributesUpdated(userPoolName string, userName string) string {
+func testAccUserConfig_confirm(userPoolName, userName string) string {
 	return fmt.Sprintf(`
 resource "aws_cognito_user_pool" "test" {
   name = %[1]q
-
-  schema {
-    name                     = "one"
-    attribute_data_type      = "String"
-    mutable                  = true
-    required                 = false
-    developer_only_attribute = false
-    string_attribute_constraints {}
-  }
-  schema {
-    name                     = "two"
-    attribute_data_type      = "String"
-    mutable                  = true
-    required                 = false
-    developer_only_attribute = false
-    string_attribute_constraints {}
-  }
-  schema {
-    name                     = "three"
-    attribute_data_type      = "String"
-    mutable                  = true
-    required                 = false
-    developer_only_attribute = false
-    string_attribute_constraints {}
-  }
-  schema {
-    name                     = "four"
-    attribute_data_type      = "String"
-    mutable                  = true
-    required                 = false
-    developer_only_attribute = false
-    string_attribute_constraints {}
-  }
 }
 
 resource "aws_cognito_user" "test" {
   user_pool_id = aws_cognito_user_pool.test.id
   username     = %[2]q
-
-  attributes = {
-    two   = "2"
-    three = "three"
-    four  = "4"
-  }
+  password     = "Password1!"
+  confirm      = true
 }
 `, userPoolName, userName)
 }
 
-func testAccUserConfig_enable(userPoolName string, userName string, enabled bool) string {
+func testAccUserConfig_attributeBlocks(userPoolName, userName, email string) string {
 	return fmt.Sprintf(`
 resource "aws_cognito_user_pool" "test" {
   name = %[1]q
@@ -620,7 +4909,16 @@ resource "aws_cognito_user_pool" "test" {
 resource "aws_cognito_user" "test" {
   user_pool_id = aws_cognito_user_pool.test.id
   username     = %[2]q
-  enabled      = %[3]t
+
+  attribute {
+    name  = "email"
+    value = %[3]q
+  }
+
+  attribute {
+    name  = "name"
+    value = "test"
+  }
 }
-`, userPoolName, userName, enabled)
+`, userPoolName, userName, email)
 }
@@ -0,0 +1,128 @@
+package cognitoidp
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hashicorp/terraform-provider-aws/internal/conns"
+	"github.com/hashicorp/terraform-provider-aws/internal/errs/sdkdiag"
+	"github.com/hashicorp/terraform-provider-aws/internal/flex"
+)
+
+func DataSourceUsers() *schema.Resource {
+	return &schema.Resource{
+		ReadWithoutTimeout: dataSourceUsersRead,
+
+		Schema: map[string]*schema.Schema{
+			"attributes_to_get": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"filter": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 60),
+			},
+			"max_results": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"user_pool_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"users": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"attributes": {
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"username": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := meta.(*conns.AWSClient).CognitoIDPConn()
+
+	userPoolID := d.Get("user_pool_id").(string)
+	input := &cognitoidentityprovider.ListUsersInput{
+		UserPoolId: aws.String(userPoolID),
+	}
+
+	if v, ok := d.GetOk("attributes_to_get"); ok && v.(*schema.Set).Len() > 0 {
+		for _, v := range flex.ExpandStringValueSet(v.(*schema.Set)) {
+			input.AttributesToGet = append(input.AttributesToGet, aws.String(userAttributeAPIName(v)))
+		}
+	}
+
+	if v, ok := d.GetOk("filter"); ok {
+		input.Filter = aws.String(v.(string))
+	}
+
+	if v, ok := d.GetOk("limit"); ok {
+		input.Limit = aws.Int64(int64(v.(int)))
+	}
+
+	users, err := FindUsersWithMaxResults(ctx, conn, input, d.Get("max_results").(int))
+
+	if err != nil {
+		return sdkdiag.AppendErrorf(diags, "listing Cognito User Pool (%s) users: %s", userPoolID, err)
+	}
+
+	d.SetId(userPoolID)
+
+	if err := d.Set("users", flattenUsers(users)); err != nil {
+		return sdkdiag.AppendErrorf(diags, "setting users: %s", err)
+	}
+
+	return diags
+}
+
+func flattenUsers(apiObjects []*cognitoidentityprovider.UserType) []interface{} {
+	tfList := make([]interface{}, 0, len(apiObjects))
+
+	for _, apiObject := range apiObjects {
+		if apiObject == nil {
+			continue
+		}
+
+		tfList = append(tfList, map[string]interface{}{
+			"attributes": flattenUserAttributes(apiObject.Attributes, false),
+			"enabled":    aws.BoolValue(apiObject.Enabled),
+			"status":     aws.StringValue(apiObject.UserStatus),
+			"username":   aws.StringValue(apiObject.Username),
+		})
+	}
+
+	return tfList
+}
@@ -0,0 +1,162 @@
+package cognitoidp_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	sdkacctest "github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-aws/internal/acctest"
+)
+
+func TestAccCognitoIDPUsersDataSource_basic(t *testing.T) {
+	ctx := acctest.Context(t)
+	rName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	dataSourceName := "data.aws_cognito_users.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUsersDataSourceConfig_basic(rName, 3),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "users.#", "3"),
+					resource.TestCheckTypeSetElemNestedAttrs(dataSourceName, "users.*", map[string]string{
+						"username":         rName + "-0",
+						"enabled":          "true",
+						"status":           cognitoidentityprovider.UserStatusTypeForceChangePassword,
+						"attributes.email": "0@example.com",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUsersDataSource_filter(t *testing.T) {
+	ctx := acctest.Context(t)
+	rName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	dataSourceName := "data.aws_cognito_users.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUsersDataSourceConfig_filter(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "users.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "users.0.username", rName+"-1"),
+					resource.TestCheckResourceAttr(dataSourceName, "users.0.attributes.%", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "users.0.attributes.email", "1@example.com"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUsersDataSource_pagination(t *testing.T) {
+	ctx := acctest.Context(t)
+	rName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	dataSourceName := "data.aws_cognito_users.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// ListUsers returns at most 60 users per page.
+				Config: testAccUsersDataSourceConfig_basic(rName, 65),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "users.#", "65"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCognitoIDPUsersDataSource_maxResults(t *testing.T) {
+	ctx := acctest.Context(t)
+	rName := sdkacctest.RandomWithPrefix(acctest.ResourcePrefix)
+	dataSourceName := "data.aws_cognito_users.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, cognitoidentityprovider.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// With 2 users per page, 3 pages are read to return 5 users.
+				Config: testAccUsersDataSourceConfig_maxResults(rName, 7, 2, 5),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "limit", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "max_results", "5"),
+					resource.TestCheckResourceAttr(dataSourceName, "users.#", "5"),
+				),
+			},
+			{
+				Config:      testAccUsersDataSourceConfig_maxResults(rName, 7, 61, 5),
+				ExpectError: regexp.MustCompile(`expected limit to be in the range \(1 - 60\)`),
+			},
+		},
+	})
+}
+
+func testAccUsersDataSourceConfig_base(rName string, count int) string {
+	return fmt.Sprintf(`
+resource "aws_cognito_user_pool" "test" {
+  name = %[1]q
+}
+
+resource "aws_cognito_user" "test" {
+  count = %[2]d
+
+  user_pool_id = aws_cognito_user_pool.test.id
+  username     = "%[1]s-${count.index}"
+
+  attributes = {
+    email = "${count.index}@example.com"
+  }
+}
+`, rName, count)
+}
+
+func testAccUsersDataSourceConfig_basic(rName string, count int) string {
+	return acctest.ConfigCompose(testAccUsersDataSourceConfig_base(rName, count), `
+data "aws_cognito_users" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+
+  depends_on = [aws_cognito_user.test]
+}
+`)
+}
+
+func testAccUsersDataSourceConfig_maxResults(rName string, count, limit, maxResults int) string {
+	return acctest.ConfigCompose(testAccUsersDataSourceConfig_base(rName, count), fmt.Sprintf(`
+data "aws_cognito_users" "test" {
+  user_pool_id = aws_cognito_user_pool.test.id
+  limit        = %[1]d
+  max_results  = %[2]d
+
+  depends_on = [aws_cognito_user.test]
+}
+`, limit, maxResults))
+}
+
+func testAccUsersDataSourceConfig_filter(rName string) string {
+	return acctest.ConfigCompose(testAccUsersDataSourceConfig_base(rName, 3), `
+data "aws_cognito_users" "test" {
+  user_pool_id      = aws_cognito_user_pool.test.id
+  filter            = "email = \"1@example.com\""
+  attributes_to_get = ["email"]
+
+  depends_on = [aws_cognito_user.test]
+}
+`)
+}
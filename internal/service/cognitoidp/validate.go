@@ -36,6 +36,13 @@ func validUserGroupName(v interface{}, k string) (ws []string, es []error) {
 	return
 }
 
+func validUserAttributesDocument(v interface{}, k string) (ws []string, es []error) {
+	if _, err := expandUserAttributesDocument(v.(string)); err != nil {
+		es = append(es, fmt.Errorf("%q: %w", k, err))
+	}
+	return
+}
+
 func validUserPoolEmailVerificationMessage(v interface{}, k string) (ws []string, es []error) {
 	value := v.(string)
 	if len(value) < 6 {
@@ -192,3 +192,37 @@ func TestValidUserPoolSMSVerificationMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestValidUserAttributesDocument(t *testing.T) {
+	t.Parallel()
+
+	validValues := []string{
+		"",
+		`{}`,
+		`{"email":"test@example.com","foo":"bar"}`,
+	}
+
+	for _, s := range validValues {
+		_, errors := validUserAttributesDocument(s, "attributes_document")
+		if len(errors) > 0 {
+			t.Fatalf("%q should be a valid Cognito User attributes document: %v", s, errors)
+		}
+	}
+
+	invalidValues := []string{
+		`not json`,
+		`["foo"]`,
+		`{"foo":{"bar":"baz"}}`,
+		`{"foo":["bar"]}`,
+		`{"foo":1}`,
+		`{"foo":true}`,
+		`{"foo":null}`,
+	}
+
+	for _, s := range invalidValues {
+		_, errors := validUserAttributesDocument(s, "attributes_document")
+		if len(errors) == 0 {
+			t.Fatalf("%q should not be a valid Cognito User attributes document: %v", s, errors)
+		}
+	}
+}
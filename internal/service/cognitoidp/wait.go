@@ -2,10 +2,14 @@ package cognitoidp
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-aws/internal/tfresource"
 )
 
 const (
@@ -55,3 +59,107 @@ func waitUserPoolDomainCreated(ctx context.Context, conn *cognitoidentityprovide
 
 	return nil, err
 }
+
+// waitUserCreated waits until a newly created user's status settles.
+func waitUserCreated(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string, timeout time.Duration) (*cognitoidentityprovider.AdminGetUserOutput, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{
+			cognitoidentityprovider.UserStatusTypeUnknown,
+		},
+		Target: []string{
+			cognitoidentityprovider.UserStatusTypeConfirmed,
+			cognitoidentityprovider.UserStatusTypeForceChangePassword,
+			cognitoidentityprovider.UserStatusTypeResetRequired,
+		},
+		Refresh:        statusUser(ctx, conn, userPoolID, username),
+		Timeout:        timeout,
+		NotFoundChecks: 20,
+	}
+
+	outputRaw, err := stateConf.WaitForStateContext(ctx)
+
+	if output, ok := outputRaw.(*cognitoidentityprovider.AdminGetUserOutput); ok {
+		return output, err
+	}
+
+	return nil, err
+}
+
+// waitUserDeleted waits until the user can no longer be found.
+// UserNotFoundException is treated as confirmation of deletion.
+func waitUserDeleted(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string, timeout time.Duration) error {
+	_, err := tfresource.RetryUntilNotFound(ctx, timeout, func() (interface{}, error) {
+		return FindUserByTwoPartKey(ctx, conn, userPoolID, username)
+	})
+
+	return err
+}
+
+// waitUserEnabledState waits until a read of the user reflects the enabled flag, so that dependent resources don't read a stale value.
+func waitUserEnabledState(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string, enabled bool, timeout time.Duration) error {
+	return tfresource.WaitUntil(ctx, timeout, func() (bool, error) {
+		output, err := FindUserByTwoPartKey(ctx, conn, userPoolID, username)
+
+		if err != nil {
+			return false, err
+		}
+
+		return aws.BoolValue(output.Enabled) == enabled, nil
+	}, tfresource.WaitOpts{
+		ContinuousTargetOccurence: 2,
+		MinTimeout:                2 * time.Second,
+	})
+}
+
+// waitUserAttributesPropagated waits until a read of the user reflects the updated and deleted attributes.
+func waitUserAttributesPropagated(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string, updated []*cognitoidentityprovider.AttributeType, deleted []*string, timeout time.Duration) error {
+	return tfresource.WaitUntil(ctx, timeout, func() (bool, error) {
+		output, err := FindUserByTwoPartKey(ctx, conn, userPoolID, username)
+
+		if err != nil {
+			return false, err
+		}
+
+		return userAttributesPropagated(output.UserAttributes, updated, deleted), nil
+	}, tfresource.WaitOpts{
+		ContinuousTargetOccurence: 2,
+		MinTimeout:                2 * time.Second,
+	})
+}
+
+// waitUserCreateVerified waits for the user to match its configuration, allowing for eventual consistency.
+// Only a user or attributes not yet visible are waited for. Any value that differs from the configuration is returned as an error.
+func waitUserCreateVerified(ctx context.Context, conn *cognitoidentityprovider.CognitoIdentityProvider, userPoolID, username string, attributes map[string]interface{}, rawNames, enabled bool, desiredStatus string) error {
+	var missing []string
+
+	err := tfresource.WaitUntil(ctx, propagationTimeout, func() (bool, error) {
+		output, err := FindUserByTwoPartKey(ctx, conn, userPoolID, username)
+
+		if tfresource.NotFound(err) {
+			missing = []string{"user not found"}
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		var diverged []string
+		diverged, missing = userCreateDivergence(output, attributes, rawNames, enabled, desiredStatus)
+
+		if len(diverged) > 0 {
+			return false, fmt.Errorf("user diverges from configuration, possibly altered by a Lambda trigger: %s", strings.Join(diverged, "; "))
+		}
+
+		return len(missing) == 0, nil
+	}, tfresource.WaitOpts{
+		ContinuousTargetOccurence: 2,
+		MinTimeout:                2 * time.Second,
+	})
+
+	if tfresource.TimedOut(err) && len(missing) > 0 {
+		return fmt.Errorf("user not fully visible after %s: %s", propagationTimeout, strings.Join(missing, "; "))
+	}
+
+	return err
+}
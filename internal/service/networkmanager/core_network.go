@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math/rand"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -27,6 +28,15 @@ const (
 	coreNetworkStatePending = "PENDING"
 )
 
+const (
+	// Polling for a core network update starts at coreNetworkUpdatedMinPollInterval and doubles up to
+	// coreNetworkUpdatedMaxPollInterval, so that large core networks don't risk throttling.
+	coreNetworkUpdatedMinPollInterval = 10 * time.Second
+	coreNetworkUpdatedMaxPollInterval = 60 * time.Second
+	// Each interval is shortened by up to this fraction so that concurrent waiters don't poll in step.
+	coreNetworkUpdatedPollJitter = 0.2
+)
+
 // This resource is explicitly NOT exported from the provider until design is finalized.
 // Its Delete handler is used by sweepers.
 func ResourceCoreNetwork() *schema.Resource {
@@ -248,7 +258,7 @@ func resourceCoreNetworkRead(ctx context.Context, d *schema.ResourceData, meta i
 	} else if err != nil {
 		return diag.Errorf("reading Network Manager Core Network (%s) policy: %s", d.Id(), err)
 	} else {
-		encodedPolicyDocument, err := protocol.EncodeJSONValue(coreNetworkPolicy.PolicyDocument, protocol.NoEscape)
+		encodedPolicyDocument, err := flattenCoreNetworkPolicyDocument(coreNetworkPolicy.PolicyDocument)
 
 		if err != nil {
 			return diag.Errorf("encoding Network Manager Core Network (%s) policy document: %s", d.Id(), err)
@@ -290,7 +300,7 @@ func resourceCoreNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta
 	}
 
 	if d.HasChange("policy_document") {
-		err := PutAndExecuteCoreNetworkPolicy(ctx, conn, d.Id(), d.Get("policy_document").(string))
+		_, err := PutAndExecuteCoreNetworkPolicy(ctx, conn, d.Id(), d.Get("policy_document").(string), "", d.Timeout(schema.TimeoutUpdate))
 
 		if err != nil {
 			return diag.FromErr(err)
@@ -310,7 +320,7 @@ func resourceCoreNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta
 			}
 
 			policyDocumentTarget := buildCoreNetworkBasePolicyDocument(region)
-			err := PutAndExecuteCoreNetworkPolicy(ctx, conn, d.Id(), policyDocumentTarget)
+			_, err := PutAndExecuteCoreNetworkPolicy(ctx, conn, d.Id(), policyDocumentTarget, "", d.Timeout(schema.TimeoutUpdate))
 
 			if err != nil {
 				return diag.FromErr(err)
@@ -397,6 +407,64 @@ func FindCoreNetworkPolicyByID(ctx context.Context, conn *networkmanager.Network
 		CoreNetworkId: aws.String(id),
 	}
 
+	return findCoreNetworkPolicy(ctx, conn, input)
+}
+
+func FindCoreNetworkPolicyByAlias(ctx context.Context, conn *networkmanager.NetworkManager, id, alias string) (*networkmanager.CoreNetworkPolicy, error) {
+	input := &networkmanager.GetCoreNetworkPolicyInput{
+		Alias:         aws.String(alias),
+		CoreNetworkId: aws.String(id),
+	}
+
+	return findCoreNetworkPolicy(ctx, conn, input)
+}
+
+func FindCoreNetworkPolicyByVersionID(ctx context.Context, conn *networkmanager.NetworkManager, id string, policyVersionID int64) (*networkmanager.CoreNetworkPolicy, error) {
+	input := &networkmanager.GetCoreNetworkPolicyInput{
+		CoreNetworkId:   aws.String(id),
+		PolicyVersionId: aws.Int64(policyVersionID),
+	}
+
+	return findCoreNetworkPolicy(ctx, conn, input)
+}
+
+func FindCoreNetworkChangeSet(ctx context.Context, conn *networkmanager.NetworkManager, id string, policyVersionID int64) ([]*networkmanager.CoreNetworkChange, error) {
+	input := &networkmanager.GetCoreNetworkChangeSetInput{
+		CoreNetworkId:   aws.String(id),
+		PolicyVersionId: aws.Int64(policyVersionID),
+	}
+
+	var output []*networkmanager.CoreNetworkChange
+
+	err := conn.GetCoreNetworkChangeSetPagesWithContext(ctx, input, func(page *networkmanager.GetCoreNetworkChangeSetOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, v := range page.CoreNetworkChanges {
+			if v != nil {
+				output = append(output, v)
+			}
+		}
+
+		return !lastPage
+	})
+
+	if tfawserr.ErrCodeEquals(err, networkmanager.ErrCodeResourceNotFoundException) {
+		return nil, &resource.NotFoundError{
+			LastError:   err,
+			LastRequest: input,
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func findCoreNetworkPolicy(ctx context.Context, conn *networkmanager.NetworkManager, input *networkmanager.GetCoreNetworkPolicyInput) (*networkmanager.CoreNetworkPolicy, error) {
 	output, err := conn.GetCoreNetworkPolicyWithContext(ctx, input)
 
 	if tfawserr.ErrCodeEquals(err, networkmanager.ErrCodeResourceNotFoundException) {
@@ -433,6 +501,104 @@ func statusCoreNetworkState(ctx context.Context, conn *networkmanager.NetworkMan
 	}
 }
 
+// statusCoreNetworkStateWithProgress is statusCoreNetworkState that also logs the progress of the policy execution.
+// The policy execution is only read when the core network state changes, so that polls don't make extra API calls.
+func statusCoreNetworkStateWithProgress(ctx context.Context, conn *networkmanager.NetworkManager, id string) resource.StateRefreshFunc {
+	refresh := statusCoreNetworkState(ctx, conn, id)
+	var lastState string
+
+	return func() (interface{}, string, error) {
+		output, state, err := refresh()
+
+		if err != nil || output == nil {
+			return output, state, err
+		}
+
+		if state == lastState {
+			log.Printf("[DEBUG] Core Network (%s) state: %s", id, state)
+		} else {
+			logCoreNetworkExecutionProgress(ctx, conn, id, state)
+			lastState = state
+		}
+
+		return output, state, nil
+	}
+}
+
+// logCoreNetworkExecutionProgress logs the core network state and the state of the latest policy version's change set,
+// with the number of segment changes in the change set while it's executing.
+// Failures to read the change set are logged and otherwise ignored.
+func logCoreNetworkExecutionProgress(ctx context.Context, conn *networkmanager.NetworkManager, id, state string) {
+	policy, err := FindCoreNetworkPolicyByAlias(ctx, conn, id, networkmanager.CoreNetworkPolicyAliasLatest)
+
+	if err != nil {
+		log.Printf("[DEBUG] Core Network (%s) state: %s, unable to read policy: %s", id, state, err)
+		return
+	}
+
+	policyVersionID := aws.Int64Value(policy.PolicyVersionId)
+	changeSetState := aws.StringValue(policy.ChangeSetState)
+	if changeSetState != networkmanager.ChangeSetStateExecuting {
+		log.Printf("[DEBUG] Core Network (%s) state: %s, policy version %d change set state: %s", id, state, policyVersionID, changeSetState)
+		return
+	}
+
+	changes, err := FindCoreNetworkChangeSet(ctx, conn, id, policyVersionID)
+
+	if err != nil {
+		log.Printf("[DEBUG] Core Network (%s) state: %s, policy version %d change set state: %s, unable to read change set: %s", id, state, policyVersionID, changeSetState, err)
+		return
+	}
+
+	log.Printf("[DEBUG] Core Network (%s) state: %s, policy version %d change set state: %s, segment changes in change set: %d", id, state, policyVersionID, changeSetState, coreNetworkChangeSetSegmentCount(changes))
+}
+
+// coreNetworkChangeSetSegmentCount returns the number of segment changes in a change set.
+func coreNetworkChangeSetSegmentCount(changes []*networkmanager.CoreNetworkChange) int {
+	count := 0
+
+	for _, change := range changes {
+		if change != nil && aws.StringValue(change.Type) == networkmanager.ChangeTypeCoreNetworkSegment {
+			count++
+		}
+	}
+
+	return count
+}
+
+func statusCoreNetworkPolicyChangeSetState(ctx context.Context, conn *networkmanager.NetworkManager, id string, policyVersionID int64) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		output, err := FindCoreNetworkPolicyByVersionID(ctx, conn, id, policyVersionID)
+
+		if tfresource.NotFound(err) {
+			return nil, "", nil
+		}
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		return output, aws.StringValue(output.ChangeSetState), nil
+	}
+}
+
+func waitCoreNetworkPolicyGenerated(ctx context.Context, conn *networkmanager.NetworkManager, id string, policyVersionID int64, timeout time.Duration) (*networkmanager.CoreNetworkPolicy, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{networkmanager.ChangeSetStatePendingGeneration},
+		Target:  []string{networkmanager.ChangeSetStateReadyToExecute},
+		Timeout: timeout,
+		Refresh: statusCoreNetworkPolicyChangeSetState(ctx, conn, id, policyVersionID),
+	}
+
+	outputRaw, err := stateConf.WaitForStateContext(ctx)
+
+	if output, ok := outputRaw.(*networkmanager.CoreNetworkPolicy); ok {
+		return output, err
+	}
+
+	return nil, err
+}
+
 func waitCoreNetworkCreated(ctx context.Context, conn *networkmanager.NetworkManager, id string, timeout time.Duration) (*networkmanager.CoreNetwork, error) {
 	stateConf := &resource.StateChangeConf{
 		Pending: []string{networkmanager.CoreNetworkStateCreating, coreNetworkStatePending},
@@ -455,6 +621,29 @@ func waitCoreNetworkUpdated(ctx context.Context, conn *networkmanager.NetworkMan
 		Pending: []string{networkmanager.CoreNetworkStateUpdating},
 		Target:  []string{networkmanager.CoreNetworkStateAvailable},
 		Timeout: timeout,
+		// The refresh function backs off between polls itself, so the StateChangeConf doesn't add its own wait.
+		PollInterval: time.Millisecond,
+		Refresh: refreshWithBackoff(ctx, statusCoreNetworkStateWithProgress(ctx, conn, id), func(attempt int) time.Duration {
+			return coreNetworkUpdatedPollInterval(attempt, rand.Float64()) //nolint:gosec // Jitter doesn't need a secure random number.
+		}),
+	}
+
+	outputRaw, err := stateConf.WaitForStateContext(ctx)
+
+	if output, ok := outputRaw.(*networkmanager.CoreNetwork); ok {
+		return output, err
+	}
+
+	return nil, err
+}
+
+// waitCoreNetworkReady waits for a core network that is still being created or updated to become AVAILABLE,
+// the only state in which it accepts policy changes.
+func waitCoreNetworkReady(ctx context.Context, conn *networkmanager.NetworkManager, id string, timeout time.Duration) (*networkmanager.CoreNetwork, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{networkmanager.CoreNetworkStateCreating, networkmanager.CoreNetworkStateUpdating, coreNetworkStatePending},
+		Target:  []string{networkmanager.CoreNetworkStateAvailable},
+		Timeout: timeout,
 		Refresh: statusCoreNetworkState(ctx, conn, id),
 	}
 
@@ -467,6 +656,46 @@ func waitCoreNetworkUpdated(ctx context.Context, conn *networkmanager.NetworkMan
 	return nil, err
 }
 
+// refreshWithBackoff waits interval(n) before the (n+1)th call of refresh. The first call isn't delayed.
+// The wait ends early, with the context's error, if the context is done.
+func refreshWithBackoff(ctx context.Context, refresh resource.StateRefreshFunc, interval func(attempt int) time.Duration) resource.StateRefreshFunc {
+	attempt := 0
+
+	return func() (interface{}, string, error) {
+		if attempt > 0 {
+			timer := time.NewTimer(interval(attempt - 1))
+			defer timer.Stop()
+
+			select {
+			case <-ctx.Done():
+				return nil, "", ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		attempt++
+
+		return refresh()
+	}
+}
+
+// coreNetworkUpdatedPollInterval returns the wait before polling a core network update again after the given attempt.
+// The interval doubles from coreNetworkUpdatedMinPollInterval up to coreNetworkUpdatedMaxPollInterval and is then
+// shortened by jitter, in [0, 1], times coreNetworkUpdatedPollJitter.
+func coreNetworkUpdatedPollInterval(attempt int, jitter float64) time.Duration {
+	interval := coreNetworkUpdatedMinPollInterval
+
+	for i := 0; i < attempt && interval < coreNetworkUpdatedMaxPollInterval; i++ {
+		interval *= 2
+	}
+
+	if interval > coreNetworkUpdatedMaxPollInterval {
+		interval = coreNetworkUpdatedMaxPollInterval
+	}
+
+	return interval - time.Duration(float64(interval)*coreNetworkUpdatedPollJitter*jitter)
+}
+
 func waitCoreNetworkDeleted(ctx context.Context, conn *networkmanager.NetworkManager, id string, timeout time.Duration) (*networkmanager.CoreNetwork, error) {
 	stateConf := &resource.StateChangeConf{
 		Pending: []string{networkmanager.CoreNetworkStateDeleting},
@@ -564,27 +793,90 @@ func flattenCoreNetworkSegments(apiObjects []*networkmanager.CoreNetworkSegment)
 	return tfList
 }
 
-func PutAndExecuteCoreNetworkPolicy(ctx context.Context, conn *networkmanager.NetworkManager, coreNetworkId, policyDocument string) error {
+// PutCoreNetworkPolicy puts a new LATEST policy version without executing its change set.
+// Without a client token a unique one is generated.
+func PutCoreNetworkPolicy(ctx context.Context, conn *networkmanager.NetworkManager, coreNetworkId, policyDocument, clientToken string) (*networkmanager.CoreNetworkPolicy, error) {
 	v, err := protocol.DecodeJSONValue(policyDocument, protocol.NoEscape)
 
 	if err != nil {
-		return fmt.Errorf("decoding Network Manager Core Network (%s) policy document: %s", coreNetworkId, err)
+		return nil, fmt.Errorf("decoding Network Manager Core Network (%s) policy document: %s", coreNetworkId, err)
+	}
+
+	if clientToken == "" {
+		clientToken = resource.UniqueId()
 	}
 
 	output, err := conn.PutCoreNetworkPolicyWithContext(ctx, &networkmanager.PutCoreNetworkPolicyInput{
-		ClientToken:    aws.String(resource.UniqueId()),
+		ClientToken:    aws.String(clientToken),
 		CoreNetworkId:  aws.String(coreNetworkId),
 		PolicyDocument: v,
 	})
 
 	if err != nil {
-		return fmt.Errorf("putting Network Manager Core Network (%s) policy: %s", coreNetworkId, err)
+		return nil, fmt.Errorf("putting Network Manager Core Network (%s) policy: %w", coreNetworkId, err)
+	}
+
+	if output == nil || output.CoreNetworkPolicy == nil {
+		return nil, fmt.Errorf("putting Network Manager Core Network (%s) policy: empty result", coreNetworkId)
+	}
+
+	return output.CoreNetworkPolicy, nil
+}
+
+// PutAndExecuteCoreNetworkPolicy puts a new policy version and executes its change set, returning the policy version ID.
+// The same client token is used for every attempt so that a retried put doesn't create another policy version.
+// Without a client token a unique one is generated.
+func PutAndExecuteCoreNetworkPolicy(ctx context.Context, conn *networkmanager.NetworkManager, coreNetworkId, policyDocument, clientToken string, timeout time.Duration) (int64, error) {
+	if clientToken == "" {
+		clientToken = resource.UniqueId()
+	}
+
+	outputRaw, err := retryCoreNetworkPolicyConflict(ctx, timeout, func() (interface{}, error) {
+		return PutCoreNetworkPolicy(ctx, conn, coreNetworkId, policyDocument, clientToken)
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	policyVersionID := aws.Int64Value(outputRaw.(*networkmanager.CoreNetworkPolicy).PolicyVersionId)
+
+	return policyVersionID, ExecuteCoreNetworkChangeSet(ctx, conn, coreNetworkId, policyVersionID)
+}
+
+// RestoreAndExecuteCoreNetworkPolicyVersion restores an earlier policy version as the LATEST policy version and executes it.
+func RestoreAndExecuteCoreNetworkPolicyVersion(ctx context.Context, conn *networkmanager.NetworkManager, coreNetworkId string, policyVersionID int64, timeout time.Duration) error {
+	input := &networkmanager.RestoreCoreNetworkPolicyVersionInput{
+		CoreNetworkId:   aws.String(coreNetworkId),
+		PolicyVersionId: aws.Int64(policyVersionID),
+	}
+
+	outputRaw, err := retryCoreNetworkPolicyConflict(ctx, timeout, func() (interface{}, error) {
+		return conn.RestoreCoreNetworkPolicyVersionWithContext(ctx, input)
+	})
+
+	if err != nil {
+		return fmt.Errorf("restoring Network Manager Core Network (%s) policy version (%d): %w", coreNetworkId, policyVersionID, err)
+	}
+
+	output := outputRaw.(*networkmanager.RestoreCoreNetworkPolicyVersionOutput)
+
+	if output == nil || output.CoreNetworkPolicy == nil {
+		return fmt.Errorf("restoring Network Manager Core Network (%s) policy version (%d): empty result", coreNetworkId, policyVersionID)
 	}
 
-	policyVersionID := aws.Int64Value(output.CoreNetworkPolicy.PolicyVersionId)
+	return ExecuteCoreNetworkChangeSet(ctx, conn, coreNetworkId, aws.Int64Value(output.CoreNetworkPolicy.PolicyVersionId))
+}
 
+// retryCoreNetworkPolicyConflict retries f while it fails because another policy change set is being put or executed concurrently.
+func retryCoreNetworkPolicyConflict(ctx context.Context, timeout time.Duration, f func() (interface{}, error)) (interface{}, error) {
+	return tfresource.RetryWhenAWSErrCodeEquals(ctx, timeout, f, networkmanager.ErrCodeConflictException)
+}
+
+// ExecuteCoreNetworkChangeSet executes the change set of an existing policy version.
+func ExecuteCoreNetworkChangeSet(ctx context.Context, conn *networkmanager.NetworkManager, coreNetworkId string, policyVersionID int64) error {
 	// new policy documents goes from Pending generation to Ready to execute
-	_, err = tfresource.RetryWhen(ctx, 4*time.Minute,
+	_, err := tfresource.RetryWhen(ctx, 4*time.Minute,
 		func() (interface{}, error) {
 			return conn.ExecuteCoreNetworkChangeSetWithContext(ctx, &networkmanager.ExecuteCoreNetworkChangeSetInput{
 				CoreNetworkId:   aws.String(coreNetworkId),
@@ -607,6 +899,18 @@ func PutAndExecuteCoreNetworkPolicy(ctx context.Context, conn *networkmanager.Ne
 	return nil
 }
 
+// flattenCoreNetworkPolicyDocument encodes a policy document returned by the API with its object keys sorted,
+// the same normalization the policy_document StateFunc applies, so that key order is never reported as drift.
+func flattenCoreNetworkPolicyDocument(apiObject aws.JSONValue) (string, error) {
+	encoded, err := protocol.EncodeJSONValue(apiObject, protocol.NoEscape)
+
+	if err != nil {
+		return "", err
+	}
+
+	return structure.NormalizeJsonString(encoded)
+}
+
 // buildCoreNetworkBasePolicyDocument returns a base policy document
 func buildCoreNetworkBasePolicyDocument(region string) string {
 	return fmt.Sprintf("{\"core-network-configuration\":{\"asn-ranges\":[\"64512-65534\"],\"edge-locations\":[{\"location\":\"%s\"}]},\"segments\":[{\"name\":\"segment\",\"description\":\"base-policy\"}],\"version\":\"2021.12\"}", region)
@@ -2,18 +2,31 @@ package networkmanager
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"log"
+	"os"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/private/protocol"
+	"github.com/aws/aws-sdk-go/service/networkmanager"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hashicorp/terraform-provider-aws/internal/conns"
 	"github.com/hashicorp/terraform-provider-aws/internal/tfresource"
 	"github.com/hashicorp/terraform-provider-aws/internal/verify"
+	"github.com/mitchellh/go-homedir"
 )
 
 func ResourceCoreNetworkPolicyAttachment() *schema.Resource {
@@ -21,17 +34,55 @@ func ResourceCoreNetworkPolicyAttachment() *schema.Resource {
 		CreateWithoutTimeout: resourceCoreNetworkPolicyAttachmentCreate,
 		ReadWithoutTimeout:   resourceCoreNetworkPolicyAttachmentRead,
 		UpdateWithoutTimeout: resourceCoreNetworkPolicyAttachmentUpdate,
-		DeleteWithoutTimeout: schema.NoopContext,
+		DeleteWithoutTimeout: resourceCoreNetworkPolicyAttachmentDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceCoreNetworkPolicyAttachmentImport,
 		},
 
+		CustomizeDiff: customdiff.Sequence(
+			resourceCoreNetworkPolicyAttachmentCustomizeDiff,
+			resourceCoreNetworkPolicyAttachmentSourceFileCustomizeDiff,
+			// A new policy document or version replaces the LIVE policy document.
+			customdiff.ComputedIf("policy_document_hash", func(_ context.Context, diff *schema.ResourceDiff, meta interface{}) bool {
+				return diff.GetRawConfig().GetAttr("source_file").IsNull() && diff.HasChanges("overrides_document", "policy_document", "policy_version_id")
+			}),
+			// Executing an existing policy version replaces the LIVE policy document.
+			customdiff.ComputedIf("policy_document", func(_ context.Context, diff *schema.ResourceDiff, meta interface{}) bool {
+				return !diff.GetRawConfig().GetAttr("policy_version_id").IsNull() && diff.HasChange("policy_version_id")
+			}),
+			// A new policy document is put with a new client token unless one is configured.
+			customdiff.ComputedIf("client_token", func(_ context.Context, diff *schema.ResourceDiff, meta interface{}) bool {
+				rawConfig := diff.GetRawConfig()
+
+				return rawConfig.GetAttr("client_token").IsNull() && rawConfig.GetAttr("policy_version_id").IsNull() && diff.HasChanges("overrides_document", "policy_document", "policy_document_hash")
+			}),
+			// A new policy document is executed as a new LIVE policy version.
+			customdiff.ComputedIf("policy_version_id", func(_ context.Context, diff *schema.ResourceDiff, meta interface{}) bool {
+				return diff.GetRawConfig().GetAttr("policy_version_id").IsNull() && diff.HasChanges("overrides_document", "policy_document", "policy_document_hash")
+			}),
+			// Executing a policy changes the core network's edges and segments.
+			customdiff.ComputedIf("edge_locations", func(_ context.Context, diff *schema.ResourceDiff, meta interface{}) bool {
+				return diff.HasChanges("overrides_document", "policy_document", "policy_document_hash", "policy_version_id")
+			}),
+			customdiff.ComputedIf("segments", func(_ context.Context, diff *schema.ResourceDiff, meta interface{}) bool {
+				return diff.HasChanges("overrides_document", "policy_document", "policy_document_hash", "policy_version_id")
+			}),
+		),
+
 		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
 			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
 		},
 
 		Schema: map[string]*schema.Schema{
+			"client_token": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringLenBetween(1, 256),
+			},
 			"core_network_id": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -41,9 +92,50 @@ func ResourceCoreNetworkPolicyAttachment() *schema.Resource {
 					validation.StringMatch(regexp.MustCompile(`^core-network-([0-9a-f]{8,17})$`), "must be a valid Core Network ID"),
 				),
 			},
-			"policy_document": {
+			"destroy_dry_run": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"revert_on_destroy"},
+			},
+			"edge_locations": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"asn": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"edge_location": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"inside_cidr_blocks": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"fail_on_orphaned_attachments": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"latest_change_set_id": {
 				Type:     schema.TypeString,
-				Required: true,
+				Computed: true,
+			},
+			"latest_executed": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"overrides_document": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"policy_version_id"},
 				ValidateFunc: validation.All(
 					validation.StringLenBetween(0, 10000000),
 					validation.StringIsJSON,
@@ -54,10 +146,107 @@ func ResourceCoreNetworkPolicyAttachment() *schema.Resource {
 					return json
 				},
 			},
+			"policy_document": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"policy_document", "policy_version_id", "source_file"},
+				ValidateFunc: validation.All(
+					validation.StringLenBetween(0, 10000000),
+					validation.StringIsJSON,
+					ValidateCoreNetworkPolicyDocument,
+				),
+				// Only semantic differences are drift: documents that differ just in key order, whitespace
+				// or the defaults AWS injects are equivalent, as the API doesn't preserve the submitted document.
+				DiffSuppressFunc: SuppressEquivalentCoreNetworkPolicyDiffs,
+				StateFunc: func(v interface{}) string {
+					json, _ := structure.NormalizeJsonString(v)
+					return json
+				},
+			},
+			"policy_document_hash": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"policy_version_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"policy_document", "policy_version_id", "source_file"},
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"post_execution_settle": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: verify.ValidDuration,
+			},
+			"revert_on_destroy": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"destroy_dry_run"},
+			},
+			"rollback_on_failure": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"segments": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"edge_locations": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"shared_segments": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"source_file": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"policy_document", "policy_version_id", "source_file"},
+			},
 			"state": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"strict_validation": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"validate_attachment_edge_locations": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"validate_only": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"policy_version_id", "rollback_on_failure"},
+			},
+			"wait_for_execution": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"write_policy_to": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
 		},
 	}
 }
@@ -68,9 +257,52 @@ func resourceCoreNetworkPolicyAttachmentCreate(ctx context.Context, d *schema.Re
 	return resourceCoreNetworkPolicyAttachmentUpdate(ctx, d, meta)
 }
 
+// resourceCoreNetworkPolicyAttachmentImport checks that the core network exists and records its LIVE policy version,
+// so that an ID that doesn't name a core network fails the import rather than leaving an empty resource in state.
+func resourceCoreNetworkPolicyAttachmentImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	conn := meta.(*conns.AWSClient).NetworkManagerConn()
+
+	coreNetwork, err := FindCoreNetworkByID(ctx, conn, d.Id())
+
+	if tfresource.NotFound(err) {
+		return nil, fmt.Errorf("Network Manager Core Network (%s) not found", d.Id())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading Network Manager Core Network (%s): %w", d.Id(), err)
+	}
+
+	d.SetId(aws.StringValue(coreNetwork.CoreNetworkId))
+	d.Set("core_network_id", coreNetwork.CoreNetworkId)
+
+	policy, err := FindCoreNetworkPolicyByAlias(ctx, conn, d.Id(), networkmanager.CoreNetworkPolicyAliasLive)
+
+	if err == nil {
+		d.Set("policy_version_id", policy.PolicyVersionId)
+	} else if !tfresource.NotFound(err) {
+		return nil, fmt.Errorf("reading Network Manager Core Network (%s) policy: %w", d.Id(), err)
+	}
+
+	d.Set("destroy_dry_run", false)
+	d.Set("fail_on_orphaned_attachments", false)
+	d.Set("revert_on_destroy", false)
+	d.Set("rollback_on_failure", false)
+	d.Set("strict_validation", false)
+	d.Set("validate_only", false)
+	d.Set("validate_attachment_edge_locations", false)
+	d.Set("wait_for_execution", true)
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceCoreNetworkPolicyAttachmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
 	conn := meta.(*conns.AWSClient).NetworkManagerConn()
 
+	// policy_document is only unset in state when reading a just-imported resource or one whose policy is read from a file.
+	sourceFile := d.Get("source_file").(string)
+	importing := d.Get("policy_document").(string) == "" && sourceFile == ""
+
 	coreNetwork, err := FindCoreNetworkByID(ctx, conn, d.Id())
 
 	if !d.IsNewResource() && tfresource.NotFound(err) {
@@ -84,41 +316,1054 @@ func resourceCoreNetworkPolicyAttachmentRead(ctx context.Context, d *schema.Reso
 	}
 
 	d.Set("core_network_id", coreNetwork.CoreNetworkId)
+
+	if err := d.Set("edge_locations", flattenCoreNetworkEdges(coreNetwork.Edges)); err != nil {
+		return diag.Errorf("setting edge_locations: %s", err)
+	}
+
+	if err := d.Set("segments", flattenCoreNetworkSegments(coreNetwork.Segments)); err != nil {
+		return diag.Errorf("setting segments: %s", err)
+	}
+
 	d.Set("state", coreNetwork.State)
 
-	// getting the policy document uses a different API call
-	coreNetworkPolicy, err := FindCoreNetworkPolicyByID(ctx, conn, d.Id())
+	// getting the policy document uses a different API call.
+	// Read the LIVE (last executed) policy version so that policies executed out-of-band show as drift.
+	coreNetworkPolicy, err := FindCoreNetworkPolicyByAlias(ctx, conn, d.Id(), networkmanager.CoreNetworkPolicyAliasLive)
 
 	if tfresource.NotFound(err) {
 		d.Set("policy_document", nil)
+		d.Set("policy_document_hash", nil)
+		d.Set("policy_version_id", nil)
 	} else if err != nil {
 		return diag.Errorf("reading Network Manager Core Network (%s) policy: %s", d.Id(), err)
 	} else {
-		encodedPolicyDocument, err := protocol.EncodeJSONValue(coreNetworkPolicy.PolicyDocument, protocol.NoEscape)
+		encodedPolicyDocument, err := flattenCoreNetworkPolicyDocument(coreNetworkPolicy.PolicyDocument)
 
 		if err != nil {
 			return diag.Errorf("encoding Network Manager Core Network (%s) policy document: %s", d.Id(), err)
 		}
 
-		d.Set("policy_document", encodedPolicyDocument)
+		if err := coreNetworkPolicyDocumentRoundTripError(coreNetworkPolicy.PolicyDocument, encodedPolicyDocument); err != nil {
+			log.Printf("[WARN] Network Manager Core Network (%s) policy document may not round-trip cleanly: %s", d.Id(), err)
+		}
+
+		policyDocumentHash, err := coreNetworkPolicyDocumentHash(encodedPolicyDocument)
+
+		if err != nil {
+			return diag.Errorf("hashing Network Manager Core Network (%s) policy document: %s", d.Id(), err)
+		}
+
+		// Only the hash of a policy document read from a file is kept in state.
+		// The configured policy document is kept while the LIVE policy is the result of merging the overrides into it,
+		// or only differs from it in the defaults AWS injects.
+		if sourceFile != "" {
+			d.Set("policy_document", nil)
+		} else if v := d.Get("policy_document").(string); !coreNetworkPolicyOverridesApplied(v, d.Get("overrides_document").(string), encodedPolicyDocument) && !coreNetworkPolicyDocumentsEquivalent(v, encodedPolicyDocument) {
+			d.Set("policy_document", encodedPolicyDocument)
+		}
+		d.Set("policy_document_hash", policyDocumentHash)
+
+		policyVersionID := aws.Int64Value(coreNetworkPolicy.PolicyVersionId)
+
+		// Executing an earlier policy version restores it as a new LIVE version with the same document, which isn't drift.
+		v := int64(d.Get("policy_version_id").(int))
+		if v > 0 && v != policyVersionID {
+			if policy, err := FindCoreNetworkPolicyByVersionID(ctx, conn, d.Id(), v); err == nil && reflect.DeepEqual(policy.PolicyDocument, coreNetworkPolicy.PolicyDocument) {
+				policyVersionID = v
+			}
+		}
+
+		// A LIVE policy version other than the one in state was executed out-of-band, so the file is refreshed.
+		if filename := d.Get("write_policy_to").(string); filename != "" && v != policyVersionID {
+			if _, err := writeCoreNetworkPolicyFile(filename, encodedPolicyDocument); err != nil {
+				return diag.Errorf("writing Network Manager Core Network (%s) policy document (%s): %s", d.Id(), filename, err)
+			}
+		}
+
+		d.Set("policy_version_id", policyVersionID)
 	}
-	return nil
+
+	latestPolicy, err := FindCoreNetworkPolicyByAlias(ctx, conn, d.Id(), networkmanager.CoreNetworkPolicyAliasLatest)
+
+	if tfresource.NotFound(err) {
+		d.Set("latest_change_set_id", nil)
+		d.Set("latest_executed", false)
+	} else if err != nil {
+		return diag.Errorf("reading Network Manager Core Network (%s) LATEST policy: %s", d.Id(), err)
+	} else {
+		d.Set("latest_change_set_id", coreNetworkChangeSetID(aws.Int64Value(latestPolicy.PolicyVersionId)))
+		d.Set("latest_executed", coreNetworkPolicyExecutionError(latestPolicy) == nil)
+
+		if importing && coreNetworkPolicyHasStagedChanges(coreNetworkPolicy, latestPolicy) {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Network Manager Core Network (%s) has staged policy changes", d.Id()),
+				Detail: fmt.Sprintf("The LATEST policy version (%d) differs from the LIVE policy version (%d) and has not been executed. "+
+					"The next apply reconciles the core network toward the configured policy_document.", aws.Int64Value(latestPolicy.PolicyVersionId), aws.Int64Value(coreNetworkPolicy.PolicyVersionId)),
+			})
+		}
+	}
+
+	return diags
 }
 
 func resourceCoreNetworkPolicyAttachmentUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
 	conn := meta.(*conns.AWSClient).NetworkManagerConn()
 
-	if d.HasChange("policy_document") {
-		err := PutAndExecuteCoreNetworkPolicy(ctx, conn, d.Id(), d.Get("policy_document").(string))
+	// Create delegates to Update, so use the timeout of the operation being performed.
+	timeout := d.Timeout(schema.TimeoutUpdate)
+	if d.IsNewResource() {
+		timeout = d.Timeout(schema.TimeoutCreate)
+	}
+
+	// Without this check, a core network deleted since the last refresh fails deep inside the policy execution.
+	// A new attachment's core network may have just been created in the same apply, so it isn't checked.
+	if !d.IsNewResource() {
+		coreNetwork, err := FindCoreNetworkByID(ctx, conn, d.Id())
+
+		if tfresource.NotFound(err) || (err == nil && aws.StringValue(coreNetwork.State) == networkmanager.CoreNetworkStateDeleting) {
+			return diag.Errorf("Network Manager Core Network (%s) not found", d.Id())
+		}
+
+		if err != nil {
+			return diag.Errorf("reading Network Manager Core Network (%s): %s", d.Id(), err)
+		}
+	}
+
+	// The LIVE policy version being replaced, recorded before any put so that it can be rolled back to.
+	o, _ := d.GetChange("policy_version_id")
+	previousPolicyVersionID := int64(o.(int))
+
+	var executed bool
+	var policyDocument string
+
+	switch {
+	case d.Get("source_file").(string) != "":
+		if d.HasChanges("overrides_document", "policy_document_hash", "source_file") {
+			filename := d.Get("source_file").(string)
+			v, err := resourceCoreNetworkPolicyAttachmentLoadFileContent(filename)
+
+			if err != nil {
+				return diag.Errorf("loading Network Manager Core Network (%s) policy document (%s): %s", d.Id(), filename, err)
+			}
+
+			policyDocument = v
+		}
+	// policy_version_id is only configured when executing an existing policy version instead of a document.
+	case d.GetRawConfig().GetAttr("policy_version_id").IsNull():
+		if d.HasChanges("overrides_document", "policy_document") {
+			policyDocument = d.Get("policy_document").(string)
+		}
+	case d.HasChange("policy_version_id"):
+		if _, err := waitCoreNetworkReady(ctx, conn, d.Id(), timeout); err != nil {
+			return diag.Errorf("waiting for Network Manager Core Network (%s) to accept policy changes: %s", d.Id(), err)
+		}
+
+		v, err := executeCoreNetworkPolicyVersion(ctx, conn, d.Id(), int64(d.Get("policy_version_id").(int)), timeout)
+
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+
+		executed = v
+	}
+
+	if policyDocument != "" {
+		v, err := mergeCoreNetworkPolicyOverrides(policyDocument, d.Get("overrides_document").(string))
+
+		if err != nil {
+			return diag.Errorf("merging Network Manager Core Network (%s) policy overrides: %s", d.Id(), err)
+		}
+
+		policyDocument = v
+
+		warnings, errs := validCoreNetworkPolicy(policyDocument, d.Get("strict_validation").(bool))
+
+		for _, w := range warnings {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("validating Network Manager Core Network (%s) policy document: %s", d.Id(), w),
+			})
+		}
+
+		for _, err := range errs {
+			diags = append(diags, diag.Errorf("validating Network Manager Core Network (%s) policy document: %s", d.Id(), err)...)
+		}
+
+		if diags.HasError() {
+			return diags
+		}
+
+		if d.Get("validate_attachment_edge_locations").(bool) {
+			diags = append(diags, coreNetworkPolicyOrphanedAttachmentsDiags(ctx, conn, d.Id(), policyDocument)...)
+		}
+
+		clientToken := d.Get("client_token").(string)
+
+		if d.GetRawConfig().GetAttr("client_token").IsNull() {
+			// The token is derived from the LIVE policy version being replaced so that re-putting an earlier document
+			// after other changes isn't mistaken for a retry.
+			v, err := coreNetworkPolicyClientToken(d.Id(), previousPolicyVersionID, policyDocument)
+
+			if err != nil {
+				return diag.Errorf("generating Network Manager Core Network (%s) policy client token: %s", d.Id(), err)
+			}
+
+			clientToken = v
+		}
+
+		// A validated policy is left as the LATEST policy version without being executed, so the LIVE policy is unchanged.
+		if d.Get("validate_only").(bool) {
+			diags = append(diags, validateCoreNetworkPolicy(ctx, conn, d.Id(), policyDocument, clientToken, timeout)...)
+
+			if diags.HasError() {
+				return diags
+			}
+
+			d.Set("client_token", clientToken)
+
+			return append(diags, resourceCoreNetworkPolicyAttachmentReadAfterUpdate(ctx, d, meta)...)
+		}
+
+		// A core network that is still being created, or updated by another policy execution, rejects policy changes.
+		// Only executions wait, as validate_only and changes to local-only arguments leave the LIVE policy unchanged.
+		if _, err := waitCoreNetworkReady(ctx, conn, d.Id(), timeout); err != nil {
+			return append(diags, diag.Errorf("waiting for Network Manager Core Network (%s) to accept policy changes: %s", d.Id(), err)...)
+		}
+
+		policyVersionID, err := PutAndExecuteCoreNetworkPolicy(ctx, conn, d.Id(), policyDocument, clientToken, timeout)
 
 		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+
+		d.Set("client_token", clientToken)
+		d.Set("latest_change_set_id", coreNetworkChangeSetID(policyVersionID))
+
+		executed = true
+	}
+
+	// Without waiting, the policy is still executing when the core network is read.
+	if executed && d.Get("wait_for_execution").(bool) {
+		if _, err := waitCoreNetworkUpdated(ctx, conn, d.Id(), timeout); err != nil {
+			updateDiags := coreNetworkUpdateErrorDiags(ctx, conn, d.Id(), err)
+
+			if d.Get("rollback_on_failure").(bool) && previousPolicyVersionID > 0 {
+				updateDiags = rollbackCoreNetworkPolicy(ctx, conn, d.Id(), previousPolicyVersionID, timeout, updateDiags)
+			}
+
+			return append(diags, updateDiags...)
+		}
+
+		if v, ok := d.GetOk("post_execution_settle"); ok {
+			settle, _ := time.ParseDuration(v.(string))
+
+			if err := waitCoreNetworkPolicySettled(ctx, conn, d.Id(), settle); err != nil {
+				return append(diags, diag.Errorf("waiting for Network Manager Core Network (%s) policy execution to settle: %s", d.Id(), err)...)
+			}
+		}
+	}
+
+	return append(diags, resourceCoreNetworkPolicyAttachmentReadAfterUpdate(ctx, d, meta)...)
+}
+
+// resourceCoreNetworkPolicyAttachmentReadAfterUpdate reads the attachment and then writes the LIVE policy document to write_policy_to.
+// Read only writes the file when the LIVE policy version has changed, so after an apply it's always written in case the file was removed.
+func resourceCoreNetworkPolicyAttachmentReadAfterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	diags := resourceCoreNetworkPolicyAttachmentRead(ctx, d, meta)
+
+	if diags.HasError() {
+		return diags
+	}
+
+	if filename := d.Get("write_policy_to").(string); filename != "" {
+		conn := meta.(*conns.AWSClient).NetworkManagerConn()
+
+		if err := writeCoreNetworkLivePolicyFile(ctx, conn, d.Id(), filename); err != nil {
+			return append(diags, diag.Errorf("writing Network Manager Core Network (%s) policy document (%s): %s", d.Id(), filename, err)...)
+		}
+	}
+
+	return diags
+}
+
+func resourceCoreNetworkPolicyAttachmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*conns.AWSClient).NetworkManagerConn()
+
+	// A policy can't be removed from a core network, so reverting executes the minimal base policy instead.
+	policyDocument := buildCoreNetworkBasePolicyDocument(meta.(*conns.AWSClient).Region)
+
+	if d.Get("revert_on_destroy").(bool) {
+		log.Printf("[INFO] Reverting Network Manager Core Network (%s) to base policy: %s", d.Id(), policyDocument)
+
+		if _, err := PutAndExecuteCoreNetworkPolicy(ctx, conn, d.Id(), policyDocument, "", d.Timeout(schema.TimeoutDelete)); err != nil {
 			return diag.FromErr(err)
 		}
 
-		if _, err := waitCoreNetworkUpdated(ctx, conn, d.Id(), d.Timeout(schema.TimeoutUpdate)); err != nil {
-			return diag.Errorf("waiting for Network Manager Core Network (%s) update: %s", d.Id(), err)
+		if _, err := waitCoreNetworkUpdated(ctx, conn, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
+			return coreNetworkUpdateErrorDiags(ctx, conn, d.Id(), err)
+		}
+
+		return nil
+	}
+
+	// By default the policy document isn't reverted when the attachment is deleted.
+	if !d.Get("destroy_dry_run").(bool) {
+		return nil
+	}
+
+	// Generate, but don't execute, the change set for reverting the core network to its base policy.
+
+	log.Printf("[INFO] Network Manager Core Network (%s) destroy dry run, generating change set for policy: %s", d.Id(), policyDocument)
+
+	policy, err := PutCoreNetworkPolicy(ctx, conn, d.Id(), policyDocument, "")
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	policyVersionID := aws.Int64Value(policy.PolicyVersionId)
+
+	if _, err := waitCoreNetworkPolicyGenerated(ctx, conn, d.Id(), policyVersionID, d.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.Errorf("waiting for Network Manager Core Network (%s) change set (%d) generation: %s", d.Id(), policyVersionID, err)
+	}
+
+	changes, err := FindCoreNetworkChangeSet(ctx, conn, d.Id(), policyVersionID)
+
+	if err != nil {
+		return diag.Errorf("reading Network Manager Core Network (%s) change set (%d): %s", d.Id(), policyVersionID, err)
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Network Manager Core Network (%s) destroy dry run", d.Id()),
+			Detail: fmt.Sprintf("Reverting the core network policy would make the following changes: %s. "+
+				"The change set was generated as policy version %d and was not executed.", coreNetworkChangeSetSummary(changes), policyVersionID),
+		},
+	}
+}
+
+// coreNetworkChangeSetSummary returns a summary of the change set's changes by action and type.
+func coreNetworkChangeSetSummary(changes []*networkmanager.CoreNetworkChange) string {
+	counts := make(map[string]int)
+
+	for _, change := range changes {
+		if change == nil {
+			continue
+		}
+
+		counts[fmt.Sprintf("%s %s", aws.StringValue(change.Action), aws.StringValue(change.Type))]++
+	}
+
+	if len(counts) == 0 {
+		return "no changes"
+	}
+
+	keys := make([]string, 0, len(counts))
+
+	for k := range counts {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	summary := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[k], k))
+	}
+
+	return strings.Join(summary, ", ")
+}
+
+// coreNetworkUpdateErrorDiags returns the diagnostics for a failed core network update.
+// Invalid policies fail asynchronously, so any errors reported against the LATEST policy version are included.
+func coreNetworkUpdateErrorDiags(ctx context.Context, conn *networkmanager.NetworkManager, id string, err error) diag.Diagnostics {
+	summary := fmt.Sprintf("waiting for Network Manager Core Network (%s) update: %s", id, err)
+
+	policy, findErr := FindCoreNetworkPolicyByAlias(ctx, conn, id, networkmanager.CoreNetworkPolicyAliasLatest)
+
+	if findErr != nil {
+		log.Printf("[WARN] Unable to read Network Manager Core Network (%s) LATEST policy errors: %s", id, findErr)
+	}
+
+	if findErr != nil || len(policy.PolicyErrors) == 0 {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  summary,
+			},
+		}
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  summary,
+			Detail: fmt.Sprintf("Policy version %d reported the following errors:\n%s",
+				aws.Int64Value(policy.PolicyVersionId), coreNetworkPolicyErrorsDetail(policy.PolicyErrors)),
+		},
+	}
+}
+
+// validateCoreNetworkPolicy puts the policy document and waits for its change set to be generated without executing it.
+// Errors reported against the new policy version are returned as diagnostics.
+func validateCoreNetworkPolicy(ctx context.Context, conn *networkmanager.NetworkManager, id, policyDocument, clientToken string, timeout time.Duration) diag.Diagnostics {
+	outputRaw, err := retryCoreNetworkPolicyConflict(ctx, timeout, func() (interface{}, error) {
+		return PutCoreNetworkPolicy(ctx, conn, id, policyDocument, clientToken)
+	})
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	policyVersionID := aws.Int64Value(outputRaw.(*networkmanager.CoreNetworkPolicy).PolicyVersionId)
+
+	policy, err := waitCoreNetworkPolicyGenerated(ctx, conn, id, policyVersionID, timeout)
+
+	if err != nil {
+		if v, findErr := FindCoreNetworkPolicyByVersionID(ctx, conn, id, policyVersionID); findErr == nil {
+			policy = v
+		} else {
+			log.Printf("[WARN] Unable to read Network Manager Core Network (%s) policy version (%d) errors: %s", id, policyVersionID, findErr)
+		}
+	}
+
+	return coreNetworkPolicyValidationDiags(id, policyVersionID, policy, err)
+}
+
+// coreNetworkPolicyValidationDiags returns the diagnostics for a policy version that was validated but not executed.
+func coreNetworkPolicyValidationDiags(id string, policyVersionID int64, policy *networkmanager.CoreNetworkPolicy, err error) diag.Diagnostics {
+	if policy != nil && len(policy.PolicyErrors) > 0 {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("validating Network Manager Core Network (%s) policy version (%d)", id, policyVersionID),
+				Detail: fmt.Sprintf("Policy version %d reported the following errors:\n%s",
+					policyVersionID, coreNetworkPolicyErrorsDetail(policy.PolicyErrors)),
+			},
 		}
 	}
 
-	return resourceCoreNetworkPolicyAttachmentRead(ctx, d, meta)
+	if err != nil {
+		return diag.Errorf("waiting for Network Manager Core Network (%s) change set (%d) generation: %s", id, policyVersionID, err)
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Network Manager Core Network (%s) policy version (%d) validated but not executed", id, policyVersionID),
+			Detail: "validate_only is set, so the change set is ready to execute but the LIVE policy is unchanged. " +
+				"The policy_document differs from the LIVE policy until validate_only is unset and the policy is executed.",
+		},
+	}
+}
+
+// rollbackCoreNetworkPolicy restores and executes the policy version that was LIVE before a failed execution.
+// The diagnostics of the failed execution are returned, annotated with the outcome of the rollback.
+func rollbackCoreNetworkPolicy(ctx context.Context, conn *networkmanager.NetworkManager, id string, policyVersionID int64, timeout time.Duration, diags diag.Diagnostics) diag.Diagnostics {
+	log.Printf("[INFO] Rolling back Network Manager Core Network (%s) to policy version %d", id, policyVersionID)
+
+	if err := RestoreAndExecuteCoreNetworkPolicyVersion(ctx, conn, id, policyVersionID, timeout); err != nil {
+		return append(diags, diag.Errorf("rolling back Network Manager Core Network (%s) to policy version %d: %s", id, policyVersionID, err)...)
+	}
+
+	if _, err := waitCoreNetworkUpdated(ctx, conn, id, timeout); err != nil {
+		return append(diags, diag.Errorf("waiting for Network Manager Core Network (%s) rollback to policy version %d: %s", id, policyVersionID, err)...)
+	}
+
+	return coreNetworkRolledBackDiags(diags, policyVersionID)
+}
+
+// executeCoreNetworkPolicyVersion makes an existing policy version LIVE, returning whether a change set was executed.
+// Nothing is done if the version is already LIVE. Only the LATEST policy version's change set can be executed as is,
+// so any other version is first restored as a new LATEST version, as when rolling back.
+func executeCoreNetworkPolicyVersion(ctx context.Context, conn *networkmanager.NetworkManager, id string, policyVersionID int64, timeout time.Duration) (bool, error) {
+	live, err := FindCoreNetworkPolicyByAlias(ctx, conn, id, networkmanager.CoreNetworkPolicyAliasLive)
+
+	switch {
+	case err == nil && aws.Int64Value(live.PolicyVersionId) == policyVersionID:
+		log.Printf("[INFO] Network Manager Core Network (%s) policy version %d is already LIVE", id, policyVersionID)
+		return false, nil
+	case err != nil && !tfresource.NotFound(err):
+		return false, fmt.Errorf("reading Network Manager Core Network (%s) LIVE policy: %w", id, err)
+	}
+
+	policy, err := FindCoreNetworkPolicyByVersionID(ctx, conn, id, policyVersionID)
+
+	if err != nil {
+		return false, fmt.Errorf("reading Network Manager Core Network (%s) policy version (%d): %w", id, policyVersionID, err)
+	}
+
+	if !coreNetworkPolicyVersionExecutable(policy) {
+		return true, RestoreAndExecuteCoreNetworkPolicyVersion(ctx, conn, id, policyVersionID, timeout)
+	}
+
+	if _, err := waitCoreNetworkPolicyGenerated(ctx, conn, id, policyVersionID, timeout); err != nil {
+		return false, fmt.Errorf("waiting for Network Manager Core Network (%s) change set (%d) generation: %w", id, policyVersionID, err)
+	}
+
+	return true, ExecuteCoreNetworkChangeSet(ctx, conn, id, policyVersionID)
+}
+
+// coreNetworkPolicyVersionExecutable returns whether the policy version's change set can be executed without restoring it,
+// which is only the case for the LATEST version while its change set is being generated or is ready to execute.
+func coreNetworkPolicyVersionExecutable(policy *networkmanager.CoreNetworkPolicy) bool {
+	if aws.StringValue(policy.Alias) != networkmanager.CoreNetworkPolicyAliasLatest {
+		return false
+	}
+
+	switch aws.StringValue(policy.ChangeSetState) {
+	case networkmanager.ChangeSetStatePendingGeneration, networkmanager.ChangeSetStateReadyToExecute:
+		return true
+	default:
+		return false
+	}
+}
+
+// coreNetworkRolledBackDiags annotates the errors of a failed execution with the policy version that was rolled back to.
+func coreNetworkRolledBackDiags(diags diag.Diagnostics, policyVersionID int64) diag.Diagnostics {
+	annotated := make(diag.Diagnostics, 0, len(diags))
+
+	for _, v := range diags {
+		if v.Severity == diag.Error {
+			v.Summary = fmt.Sprintf("%s, rolled back to version %d", v.Summary, policyVersionID)
+		}
+
+		annotated = append(annotated, v)
+	}
+
+	return annotated
+}
+
+// coreNetworkChangeSetID returns the ID of a policy version's change set.
+// Network Manager identifies a core network's change sets by the policy version they were generated for.
+func coreNetworkChangeSetID(policyVersionID int64) string {
+	return strconv.FormatInt(policyVersionID, 10)
+}
+
+// coreNetworkPolicyErrorsDetail returns one line per policy error with its code, message and JSON path.
+func coreNetworkPolicyErrorsDetail(apiObjects []*networkmanager.CoreNetworkPolicyError) string {
+	lines := make([]string, 0, len(apiObjects))
+
+	for _, apiObject := range apiObjects {
+		if apiObject == nil {
+			continue
+		}
+
+		line := fmt.Sprintf("- %s: %s", aws.StringValue(apiObject.ErrorCode), aws.StringValue(apiObject.Message))
+
+		if v := aws.StringValue(apiObject.Path); v != "" {
+			line += fmt.Sprintf(" (path: %s)", v)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// waitCoreNetworkPolicySettled waits for the settle period and then re-checks the LATEST policy's change set,
+// catching executions that report success before failing asynchronously.
+func waitCoreNetworkPolicySettled(ctx context.Context, conn *networkmanager.NetworkManager, id string, settle time.Duration) error {
+	if settle <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(settle):
+	}
+
+	coreNetwork, err := FindCoreNetworkByID(ctx, conn, id)
+
+	if err != nil {
+		return err
+	}
+
+	if state := aws.StringValue(coreNetwork.State); state != networkmanager.CoreNetworkStateAvailable {
+		return fmt.Errorf("core network is in state %s", state)
+	}
+
+	policy, err := FindCoreNetworkPolicyByAlias(ctx, conn, id, networkmanager.CoreNetworkPolicyAliasLatest)
+
+	if err != nil {
+		return err
+	}
+
+	return coreNetworkPolicyExecutionError(policy)
+}
+
+// coreNetworkPolicyExecutionError returns an error if the policy's change set has not been executed successfully.
+func coreNetworkPolicyExecutionError(policy *networkmanager.CoreNetworkPolicy) error {
+	if state := aws.StringValue(policy.ChangeSetState); state != networkmanager.ChangeSetStateExecutionSucceeded {
+		return fmt.Errorf("policy version %d change set is in state %s", aws.Int64Value(policy.PolicyVersionId), state)
+	}
+
+	return nil
+}
+
+// resourceCoreNetworkPolicyAttachmentCustomizeDiff performs a best-effort check that a new policy document
+// does not remove edge locations that still have attachments. Listing attachments is only done when opted in.
+// A plan can't carry warnings, so orphaned attachments are logged here, reported as a warning on apply
+// and only fail the plan when fail_on_orphaned_attachments is set.
+func resourceCoreNetworkPolicyAttachmentCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("validate_attachment_edge_locations").(bool) {
+		return nil
+	}
+
+	// The core network must already exist and the new document must be known.
+	if d.Id() == "" || !d.HasChange("policy_document") || !d.NewValueKnown("policy_document") {
+		return nil
+	}
+
+	conn := meta.(*conns.AWSClient).NetworkManagerConn()
+
+	attachments, err := FindAttachments(ctx, conn, &networkmanager.ListAttachmentsInput{
+		CoreNetworkId: aws.String(d.Id()),
+	})
+
+	if err != nil {
+		log.Printf("[WARN] Unable to list Network Manager Core Network (%s) attachments, skipping edge location validation: %s", d.Id(), err)
+		return nil
+	}
+
+	orphaned, err := coreNetworkPolicyOrphanedAttachments(d.Get("policy_document").(string), attachments)
+
+	if err != nil {
+		return err
+	}
+
+	if len(orphaned) == 0 {
+		return nil
+	}
+
+	if d.Get("fail_on_orphaned_attachments").(bool) {
+		return fmt.Errorf("policy document for Network Manager Core Network (%s) removes edge locations that still have attachments: %s", d.Id(), strings.Join(orphaned, ", "))
+	}
+
+	log.Printf("[WARN] Policy document for Network Manager Core Network (%s) removes edge locations that still have attachments: %s", d.Id(), strings.Join(orphaned, ", "))
+
+	return nil
+}
+
+// coreNetworkPolicyOrphanedAttachmentsDiags returns a warning naming the attachments whose edge location the policy document removes.
+// The check is best effort, so failures to list the attachments are only logged.
+func coreNetworkPolicyOrphanedAttachmentsDiags(ctx context.Context, conn *networkmanager.NetworkManager, id, policyDocument string) diag.Diagnostics {
+	attachments, err := FindAttachments(ctx, conn, &networkmanager.ListAttachmentsInput{
+		CoreNetworkId: aws.String(id),
+	})
+
+	if err != nil {
+		log.Printf("[WARN] Unable to list Network Manager Core Network (%s) attachments, skipping edge location validation: %s", id, err)
+		return nil
+	}
+
+	orphaned, err := coreNetworkPolicyOrphanedAttachments(policyDocument, attachments)
+
+	if err != nil {
+		log.Printf("[WARN] Unable to validate Network Manager Core Network (%s) policy edge locations: %s", id, err)
+		return nil
+	}
+
+	return coreNetworkOrphanedAttachmentsWarning(id, orphaned)
+}
+
+// coreNetworkOrphanedAttachmentsWarning returns a warning naming the orphaned attachments, if there are any.
+func coreNetworkOrphanedAttachmentsWarning(id string, orphaned []string) diag.Diagnostics {
+	if len(orphaned) == 0 {
+		return nil
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Network Manager Core Network (%s) policy removes edge locations that still have attachments", id),
+			Detail: fmt.Sprintf("The following attachments are in edge locations that are not in the policy document, so the policy execution may fail: %s. "+
+				"Set fail_on_orphaned_attachments to fail the plan instead.", strings.Join(orphaned, ", ")),
+		},
+	}
+}
+
+// resourceCoreNetworkPolicyAttachmentSourceFileCustomizeDiff plans an update when the contents of source_file
+// no longer match the hash of the LIVE policy document.
+func resourceCoreNetworkPolicyAttachmentSourceFileCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("source_file") {
+		return d.SetNewComputed("policy_document_hash")
+	}
+
+	filename := d.Get("source_file").(string)
+
+	if filename == "" {
+		return nil
+	}
+
+	if !d.NewValueKnown("overrides_document") {
+		return d.SetNewComputed("policy_document_hash")
+	}
+
+	policyDocument, err := resourceCoreNetworkPolicyAttachmentLoadFileContent(filename)
+
+	if err != nil {
+		return fmt.Errorf("loading policy document (%s): %w", filename, err)
+	}
+
+	// The hash in state is that of the LIVE policy, which has the overrides merged in.
+	policyDocument, err = mergeCoreNetworkPolicyOverrides(policyDocument, d.Get("overrides_document").(string))
+
+	if err != nil {
+		return fmt.Errorf("merging policy overrides into policy document (%s): %w", filename, err)
+	}
+
+	hash, err := coreNetworkPolicyDocumentHash(policyDocument)
+
+	if err != nil {
+		return fmt.Errorf("policy document (%s) is not valid JSON: %w", filename, err)
+	}
+
+	if hash != d.Get("policy_document_hash").(string) {
+		return d.SetNew("policy_document_hash", hash)
+	}
+
+	return nil
+}
+
+func resourceCoreNetworkPolicyAttachmentLoadFileContent(filename string) (string, error) {
+	filename, err := homedir.Expand(filename)
+	if err != nil {
+		return "", err
+	}
+	fileContent, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return string(fileContent), nil
+}
+
+// writeCoreNetworkLivePolicyFile writes the core network's LIVE policy document to the file.
+// Nothing is written if the core network has no LIVE policy.
+func writeCoreNetworkLivePolicyFile(ctx context.Context, conn *networkmanager.NetworkManager, coreNetworkID, filename string) error {
+	coreNetworkPolicy, err := FindCoreNetworkPolicyByAlias(ctx, conn, coreNetworkID, networkmanager.CoreNetworkPolicyAliasLive)
+
+	if tfresource.NotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	policyDocument, err := flattenCoreNetworkPolicyDocument(coreNetworkPolicy.PolicyDocument)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = writeCoreNetworkPolicyFile(filename, policyDocument)
+
+	return err
+}
+
+// writeCoreNetworkPolicyFile writes the policy document to the file, unless the file already has that content.
+// It returns whether the file was written.
+func writeCoreNetworkPolicyFile(filename, policyDocument string) (bool, error) {
+	filename, err := homedir.Expand(filename)
+
+	if err != nil {
+		return false, err
+	}
+
+	if v, err := os.ReadFile(filename); err == nil && string(v) == policyDocument {
+		return false, nil
+	}
+
+	if err := os.WriteFile(filename, []byte(policyDocument), 0644); err != nil { //nolint:gosec // The policy document isn't secret.
+		return false, err
+	}
+
+	return true, nil
+}
+
+// coreNetworkPolicyDocumentHash returns the hex-encoded SHA-256 hash of the normalized policy document,
+// so that documents differing only in key order, whitespace or the defaults AWS injects have the same hash.
+func coreNetworkPolicyDocumentHash(policyDocument string) (string, error) {
+	var v interface{}
+
+	if err := json.Unmarshal([]byte(policyDocument), &v); err != nil {
+		return "", err
+	}
+
+	normalized, err := json.Marshal(withCoreNetworkPolicyDefaults(v))
+
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(normalized)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// mergeCoreNetworkPolicyOverrides deep-merges the overrides JSON object into the base policy document, so that
+// several core networks can share a base policy with small per-network edits.
+// Objects are merged key by key, any other override value replaces the base value and a null override removes the key.
+func mergeCoreNetworkPolicyOverrides(base, overrides string) (string, error) {
+	if overrides == "" {
+		return base, nil
+	}
+
+	var baseValue, overridesValue interface{}
+
+	if err := json.Unmarshal([]byte(base), &baseValue); err != nil {
+		return "", fmt.Errorf("decoding policy document: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(overrides), &overridesValue); err != nil {
+		return "", fmt.Errorf("decoding overrides document: %w", err)
+	}
+
+	if _, ok := overridesValue.(map[string]interface{}); !ok {
+		return "", fmt.Errorf("overrides document must be a JSON object")
+	}
+
+	merged, ok := mergeCoreNetworkPolicyValues(baseValue, overridesValue).(map[string]interface{})
+
+	if !ok {
+		return "", fmt.Errorf("merged policy document is not a JSON object")
+	}
+
+	output, err := json.Marshal(merged)
+
+	if err != nil {
+		return "", fmt.Errorf("encoding merged policy document: %w", err)
+	}
+
+	return string(output), nil
+}
+
+func mergeCoreNetworkPolicyValues(base, override interface{}) interface{} {
+	overrideMap, ok := override.(map[string]interface{})
+
+	if !ok {
+		return override
+	}
+
+	baseMap, _ := base.(map[string]interface{})
+	merged := make(map[string]interface{}, len(baseMap)+len(overrideMap))
+
+	for k, v := range baseMap {
+		merged[k] = v
+	}
+
+	for k, v := range overrideMap {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+
+		merged[k] = mergeCoreNetworkPolicyValues(merged[k], v)
+	}
+
+	return merged
+}
+
+// coreNetworkPolicyOverridesApplied returns whether the LIVE policy document is equivalent to the policy document with the overrides merged in.
+func coreNetworkPolicyOverridesApplied(policyDocument, overrides, livePolicyDocument string) bool {
+	if policyDocument == "" || overrides == "" {
+		return false
+	}
+
+	merged, err := mergeCoreNetworkPolicyOverrides(policyDocument, overrides)
+
+	if err != nil {
+		return false
+	}
+
+	return coreNetworkPolicyDocumentsEquivalent(merged, livePolicyDocument)
+}
+
+// The values AWS injects into a policy document for keys that weren't submitted, by policy document section.
+// They match the defaults of the aws_networkmanager_core_network_policy_document data source.
+var (
+	coreNetworkPolicyConfigurationDefaults = map[string]interface{}{
+		"vpn-ecmp-support": true,
+	}
+	coreNetworkPolicySegmentDefaults = map[string]interface{}{
+		"isolate-attachments":           false,
+		"require-attachment-acceptance": true,
+	}
+)
+
+// SuppressEquivalentCoreNetworkPolicyDiffs suppresses differences between core network policy documents that only differ
+// in formatting, or in the defaults AWS injects for keys that weren't submitted.
+func SuppressEquivalentCoreNetworkPolicyDiffs(k, old, new string, d *schema.ResourceData) bool {
+	return verify.SuppressEquivalentJSONDiffs(k, old, new, d) || coreNetworkPolicyDocumentsEquivalent(old, new)
+}
+
+// coreNetworkPolicyDocumentsEquivalent returns whether the policy documents have the same content
+// once the defaults AWS injects are filled in.
+func coreNetworkPolicyDocumentsEquivalent(policyDocument1, policyDocument2 string) bool {
+	var v1, v2 interface{}
+
+	if err := json.Unmarshal([]byte(policyDocument1), &v1); err != nil {
+		return false
+	}
+
+	if err := json.Unmarshal([]byte(policyDocument2), &v2); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(withCoreNetworkPolicyDefaults(v1), withCoreNetworkPolicyDefaults(v2))
+}
+
+// withCoreNetworkPolicyDefaults fills in the defaults AWS injects for the keys that aren't set in the decoded policy document.
+func withCoreNetworkPolicyDefaults(v interface{}) interface{} {
+	document, ok := v.(map[string]interface{})
+
+	if !ok {
+		return v
+	}
+
+	if configuration, ok := document["core-network-configuration"].(map[string]interface{}); ok {
+		applyCoreNetworkPolicyDefaults(configuration, coreNetworkPolicyConfigurationDefaults)
+	}
+
+	if segments, ok := document["segments"].([]interface{}); ok {
+		for _, segment := range segments {
+			if segment, ok := segment.(map[string]interface{}); ok {
+				applyCoreNetworkPolicyDefaults(segment, coreNetworkPolicySegmentDefaults)
+			}
+		}
+	}
+
+	return document
+}
+
+// applyCoreNetworkPolicyDefaults sets the defaults for the keys that aren't set in the policy document section.
+func applyCoreNetworkPolicyDefaults(section, defaults map[string]interface{}) {
+	for k, v := range defaults {
+		if _, ok := section[k]; !ok {
+			section[k] = v
+		}
+	}
+}
+
+// coreNetworkPolicyClientToken returns the client token used to put a policy document.
+// It only changes when the policy document or the policy version it replaces changes, so a retried put is idempotent.
+func coreNetworkPolicyClientToken(coreNetworkID string, previousPolicyVersionID int64, policyDocument string) (string, error) {
+	normalized, err := structure.NormalizeJsonString(policyDocument)
+
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%s", coreNetworkID, previousPolicyVersionID, normalized)))
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// coreNetworkPolicyHasStagedChanges returns whether the LATEST policy version is a newer, unexecuted
+// version whose document differs from the LIVE policy.
+func coreNetworkPolicyHasStagedChanges(live, latest *networkmanager.CoreNetworkPolicy) bool {
+	if live == nil || latest == nil {
+		return false
+	}
+
+	if aws.Int64Value(latest.PolicyVersionId) <= aws.Int64Value(live.PolicyVersionId) {
+		return false
+	}
+
+	if aws.StringValue(latest.ChangeSetState) == networkmanager.ChangeSetStateExecutionSucceeded {
+		return false
+	}
+
+	return !reflect.DeepEqual(live.PolicyDocument, latest.PolicyDocument)
+}
+
+// coreNetworkPolicyDocumentRoundTripError returns an error if the encoded policy document doesn't decode
+// to the same document it was encoded from.
+func coreNetworkPolicyDocumentRoundTripError(source aws.JSONValue, encoded string) error {
+	decoded, err := protocol.DecodeJSONValue(encoded, protocol.NoEscape)
+
+	if err != nil {
+		return fmt.Errorf("decoding policy document: %w", err)
+	}
+
+	if !reflect.DeepEqual(source, decoded) {
+		return fmt.Errorf("encoded policy document differs from the policy document returned by the API: %s", encoded)
+	}
+
+	return nil
+}
+
+// coreNetworkPolicyOrphanedAttachments returns the IDs of the attachments whose edge location is not declared
+// in the policy document's core-network-configuration.
+func coreNetworkPolicyOrphanedAttachments(policyDocument string, attachments []*networkmanager.Attachment) ([]string, error) {
+	var doc CoreNetworkPolicyDoc
+
+	if err := json.Unmarshal([]byte(policyDocument), &doc); err != nil {
+		return nil, fmt.Errorf("decoding Network Manager Core Network policy document: %w", err)
+	}
+
+	edgeLocations := make(map[string]struct{})
+
+	if doc.CoreNetworkConfiguration != nil {
+		for _, v := range doc.CoreNetworkConfiguration.EdgeLocations {
+			if v == nil {
+				continue
+			}
+
+			edgeLocations[v.Location] = struct{}{}
+		}
+	}
+
+	var orphaned []string
+
+	for _, v := range attachments {
+		if v == nil || v.EdgeLocation == nil {
+			continue
+		}
+
+		if state := aws.StringValue(v.State); state == networkmanager.AttachmentStateDeleting {
+			continue
+		}
+
+		if _, ok := edgeLocations[aws.StringValue(v.EdgeLocation)]; !ok {
+			orphaned = append(orphaned, fmt.Sprintf("%s (%s)", aws.StringValue(v.AttachmentId), aws.StringValue(v.EdgeLocation)))
+		}
+	}
+
+	sort.Strings(orphaned)
+
+	return orphaned, nil
+}
+
+func FindAttachments(ctx context.Context, conn *networkmanager.NetworkManager, input *networkmanager.ListAttachmentsInput) ([]*networkmanager.Attachment, error) {
+	var output []*networkmanager.Attachment
+
+	err := conn.ListAttachmentsPagesWithContext(ctx, input, func(page *networkmanager.ListAttachmentsOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, v := range page.Attachments {
+			if v == nil {
+				continue
+			}
+
+			output = append(output, v)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
 }
@@ -0,0 +1,67 @@
+package networkmanager
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/networkmanager"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-aws/internal/conns"
+)
+
+func DataSourceCoreNetworkPolicyAttachment() *schema.Resource {
+	return &schema.Resource{
+		ReadWithoutTimeout: dataSourceCoreNetworkPolicyAttachmentRead,
+
+		Schema: map[string]*schema.Schema{
+			"core_network_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"policy_document": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"policy_version_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceCoreNetworkPolicyAttachmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*conns.AWSClient).NetworkManagerConn()
+
+	coreNetworkID := d.Get("core_network_id").(string)
+	coreNetwork, err := FindCoreNetworkByID(ctx, conn, coreNetworkID)
+
+	if err != nil {
+		return diag.Errorf("reading Network Manager Core Network (%s): %s", coreNetworkID, err)
+	}
+
+	// As with the resource, the policy is the LIVE (last executed) policy version.
+	coreNetworkPolicy, err := FindCoreNetworkPolicyByAlias(ctx, conn, coreNetworkID, networkmanager.CoreNetworkPolicyAliasLive)
+
+	if err != nil {
+		return diag.Errorf("reading Network Manager Core Network (%s) policy: %s", coreNetworkID, err)
+	}
+
+	encodedPolicyDocument, err := flattenCoreNetworkPolicyDocument(coreNetworkPolicy.PolicyDocument)
+
+	if err != nil {
+		return diag.Errorf("encoding Network Manager Core Network (%s) policy document: %s", coreNetworkID, err)
+	}
+
+	d.SetId(coreNetworkID)
+	d.Set("core_network_id", coreNetwork.CoreNetworkId)
+	d.Set("policy_document", encodedPolicyDocument)
+	d.Set("policy_version_id", coreNetworkPolicy.PolicyVersionId)
+	d.Set("state", coreNetwork.State)
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package networkmanager_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/networkmanager"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-aws/internal/acctest"
+)
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachmentDataSource_basic(t *testing.T) {
+	dataSourceName := "data.aws_networkmanager_core_network_policy_attachment.test"
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentDataSourceConfig_basic("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(dataSourceName, "core_network_id", resourceName, "core_network_id"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "policy_document", resourceName, "policy_document"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "policy_version_id", resourceName, "policy_version_id"),
+					resource.TestCheckResourceAttr(dataSourceName, "state", networkmanager.CoreNetworkStateAvailable),
+				),
+			},
+		},
+	})
+}
+
+func testAccCoreNetworkPolicyAttachmentDataSourceConfig_basic(segmentValue string) string {
+	return acctest.ConfigCompose(testAccCoreNetworkPolicyAttachmentConfig_basic(segmentValue), `
+data "aws_networkmanager_core_network_policy_attachment" "test" {
+  core_network_id = aws_networkmanager_core_network_policy_attachment.test.core_network_id
+}
+`)
+}
@@ -2,11 +2,21 @@ package networkmanager_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/private/protocol"
 	"github.com/aws/aws-sdk-go/service/networkmanager"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/hashicorp/terraform-provider-aws/internal/acctest"
@@ -35,6 +45,13 @@ func TestAccNetworkManagerCoreNetworkPolicyAttachment_basic(t *testing.T) {
 					resource.TestCheckResourceAttrPair(resourceName, "core_network_id", "aws_networkmanager_core_network.test", "id"),
 					resource.TestCheckResourceAttrPair(resourceName, "id", "aws_networkmanager_core_network.test", "id"),
 					resource.TestCheckResourceAttr(resourceName, "state", networkmanager.CoreNetworkStateAvailable),
+					resource.TestCheckResourceAttr(resourceName, "latest_executed", "true"),
+					resource.TestCheckResourceAttrPair(resourceName, "latest_change_set_id", resourceName, "policy_version_id"),
+					resource.TestCheckResourceAttrSet(resourceName, "policy_version_id"),
+					resource.TestCheckResourceAttr(resourceName, "edge_locations.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "edge_locations.0.edge_location", acctest.Region()),
+					resource.TestCheckResourceAttr(resourceName, "segments.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "segments.0.name", originalSegmentValue),
 				),
 			},
 			{
@@ -50,12 +67,41 @@ func TestAccNetworkManagerCoreNetworkPolicyAttachment_basic(t *testing.T) {
 					resource.TestCheckResourceAttrPair(resourceName, "core_network_id", "aws_networkmanager_core_network.test", "id"),
 					resource.TestCheckResourceAttrPair(resourceName, "id", "aws_networkmanager_core_network.test", "id"),
 					resource.TestCheckResourceAttr(resourceName, "state", networkmanager.CoreNetworkStateAvailable),
+					resource.TestCheckResourceAttrSet(resourceName, "policy_version_id"),
+					resource.TestCheckResourceAttr(resourceName, "segments.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "segments.0.name", updatedSegmentValue),
 				),
 			},
 		},
 	})
 }
 
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_importNotFound(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_basic("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+				),
+			},
+			{
+				ResourceName:  resourceName,
+				ImportState:   true,
+				ImportStateId: "core-network-0123456789abcdef0",
+				ExpectError:   regexp.MustCompile(`Network Manager Core Network \(core-network-0123456789abcdef0\) not found`),
+			},
+		},
+	})
+}
+
 func TestAccNetworkManagerCoreNetworkPolicyAttachment_vpcAttachment(t *testing.T) {
 	ctx := acctest.Context(t)
 	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
@@ -78,23 +124,1218 @@ func TestAccNetworkManagerCoreNetworkPolicyAttachment_vpcAttachment(t *testing.T
 					resource.TestCheckResourceAttr(resourceName, "state", networkmanager.CoreNetworkStateAvailable),
 				),
 			},
-			{
-				ResourceName:      resourceName,
-				ImportState:       true,
-				ImportStateVerify: true,
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_basic(segmentValue),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "policy_document", fmt.Sprintf("{\"core-network-configuration\":{\"asn-ranges\":[\"65022-65534\"],\"edge-locations\":[{\"location\":\"%s\"}],\"vpn-ecmp-support\":true},\"segments\":[{\"isolate-attachments\":false,\"name\":\"%s\",\"require-attachment-acceptance\":true}],\"version\":\"2021.12\"}", acctest.Region(), segmentValue)),
+					resource.TestCheckResourceAttrPair(resourceName, "core_network_id", "aws_networkmanager_core_network.test", "id"),
+					resource.TestCheckResourceAttrPair(resourceName, "id", "aws_networkmanager_core_network.test", "id"),
+					resource.TestCheckResourceAttr(resourceName, "state", networkmanager.CoreNetworkStateAvailable),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_latestExecuted(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_basic("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "latest_executed", "true"),
+					testAccCheckCoreNetworkPolicyAttachmentStagePolicy(ctx, resourceName, "segmentValue2"),
+				),
+			},
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_basic("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "latest_executed", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_policyVersionID(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_basic("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "policy_version_id", "1"),
+					testAccCheckCoreNetworkPolicyAttachmentStagePolicy(ctx, resourceName, "segmentValue2"),
+				),
+			},
+			// Execute the staged policy version instead of putting a new document.
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_policyVersionID(2),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "policy_version_id", "2"),
+					resource.TestCheckResourceAttr(resourceName, "latest_executed", "true"),
+					resource.TestMatchResourceAttr(resourceName, "policy_document", regexp.MustCompile(`segmentValue2`)),
+				),
+			},
+			// An earlier, already executed policy version is restored before it is executed.
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_policyVersionID(1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "policy_version_id", "1"),
+					resource.TestCheckResourceAttr(resourceName, "latest_executed", "true"),
+					resource.TestMatchResourceAttr(resourceName, "policy_document", regexp.MustCompile(`segmentValue1`)),
+				),
+			},
+			{
+				Config:   testAccCoreNetworkPolicyAttachmentConfig_policyVersionID(1),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_outOfBandChange(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_basic("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					testAccCheckCoreNetworkPolicyAttachmentExecutePolicy(ctx, resourceName, "segmentValue2"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_basic("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestMatchResourceAttr(resourceName, "policy_document", regexp.MustCompile(`segmentValue1`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_injectedDefaults(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_injectedDefaults("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "policy_document", fmt.Sprintf("{\"core-network-configuration\":{\"asn-ranges\":[\"65022-65534\"],\"edge-locations\":[{\"location\":\"%s\"}]},\"segments\":[{\"name\":\"segmentValue1\"}],\"version\":\"2021.12\"}", acctest.Region())),
+				),
+			},
+			{
+				Config:   testAccCoreNetworkPolicyAttachmentConfig_injectedDefaults("segmentValue1"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_sourceFile(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+	filename := filepath.Join(t.TempDir(), "policy.json")
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					testAccCoreNetworkPolicyAttachmentWritePolicyFile(t, filename, "segmentValue1")
+				},
+				Config: testAccCoreNetworkPolicyAttachmentConfig_sourceFile(filename),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "source_file", filename),
+					resource.TestCheckResourceAttr(resourceName, "policy_document", ""),
+					resource.TestCheckResourceAttrSet(resourceName, "policy_document_hash"),
+					resource.TestCheckResourceAttr(resourceName, "segments.0.name", "segmentValue1"),
+				),
+			},
+			{
+				PreConfig: func() {
+					testAccCoreNetworkPolicyAttachmentWritePolicyFile(t, filename, "segmentValue2")
+				},
+				Config: testAccCoreNetworkPolicyAttachmentConfig_sourceFile(filename),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "policy_document", ""),
+					resource.TestCheckResourceAttr(resourceName, "segments.0.name", "segmentValue2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_importStagedChanges(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_basic("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					testAccCheckCoreNetworkPolicyAttachmentStagePolicy(ctx, resourceName, "segmentValue2"),
+				),
+			},
+			// The staged changes advisory is a warning, which doesn't fail the import.
+			// policy_document is read from the LIVE policy, not the staged LATEST one.
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_destroyDryRun(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+	coreNetworkResourceName := "aws_networkmanager_core_network.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_destroyDryRun("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "destroy_dry_run", "true"),
+				),
+			},
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_coreNetworkOnly(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentDryRunNotExecuted(ctx, coreNetworkResourceName, "segmentValue1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_revertOnDestroy(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+	coreNetworkResourceName := "aws_networkmanager_core_network.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_revertOnDestroy("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "revert_on_destroy", "true"),
+				),
+			},
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_coreNetworkOnly(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentReverted(ctx, coreNetworkResourceName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetworkManagerCoreNetworkPolicyAttachment_waitForExecution(t *testing.T) {
+	ctx := acctest.Context(t)
+	resourceName := "aws_networkmanager_core_network_policy_attachment.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.PreCheck(t) },
+		ErrorCheck:               acctest.ErrorCheck(t, networkmanager.EndpointsID),
+		ProtoV5ProviderFactories: acctest.ProtoV5ProviderFactories,
+		CheckDestroy:             testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_waitForExecution("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "wait_for_execution", "false"),
+					// The policy is still executing when the resource is read.
+					testAccCheckCoreNetworkPolicyAttachmentWaitUpdated(ctx, resourceName),
+				),
+			},
+			{
+				Config: testAccCoreNetworkPolicyAttachmentConfig_waitForExecution("segmentValue1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "state", networkmanager.CoreNetworkStateAvailable),
+					resource.TestCheckResourceAttr(resourceName, "latest_executed", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestCoreNetworkChangeSetSegmentCount(t *testing.T) {
+	t.Parallel()
+
+	changes := []*networkmanager.CoreNetworkChange{
+		{Action: aws.String(networkmanager.ChangeActionAdd), Type: aws.String(networkmanager.ChangeTypeCoreNetworkSegment)},
+		nil,
+		{Action: aws.String(networkmanager.ChangeActionModify), Type: aws.String(networkmanager.ChangeTypeCoreNetworkConfiguration)},
+		{Action: aws.String(networkmanager.ChangeActionRemove), Type: aws.String(networkmanager.ChangeTypeCoreNetworkSegment)},
+	}
+
+	if got, want := tfnetworkmanager.CoreNetworkChangeSetSegmentCount(changes), 2; got != want {
+		t.Errorf("got %d, expected %d", got, want)
+	}
+
+	if got, want := tfnetworkmanager.CoreNetworkChangeSetSegmentCount(nil), 0; got != want {
+		t.Errorf("got %d, expected %d", got, want)
+	}
+}
+
+func TestCoreNetworkChangeSetSummary(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName string
+		Changes  []*networkmanager.CoreNetworkChange
+		Expected string
+	}{
+		{
+			TestName: "no changes",
+			Expected: "no changes",
+		},
+		{
+			TestName: "changes",
+			Changes: []*networkmanager.CoreNetworkChange{
+				{Action: aws.String(networkmanager.ChangeActionRemove), Type: aws.String(networkmanager.ChangeTypeCoreNetworkSegment)},
+				nil,
+				{Action: aws.String(networkmanager.ChangeActionAdd), Type: aws.String(networkmanager.ChangeTypeCoreNetworkSegment)},
+				{Action: aws.String(networkmanager.ChangeActionRemove), Type: aws.String(networkmanager.ChangeTypeCoreNetworkSegment)},
+				{Action: aws.String(networkmanager.ChangeActionModify), Type: aws.String(networkmanager.ChangeTypeCoreNetworkConfiguration)},
+			},
+			Expected: "1 ADD CORE_NETWORK_SEGMENT, 1 MODIFY CORE_NETWORK_CONFIGURATION, 2 REMOVE CORE_NETWORK_SEGMENT",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfnetworkmanager.CoreNetworkChangeSetSummary(testCase.Changes); got != testCase.Expected {
+				t.Errorf("got %q, expected %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestCoreNetworkUpdatedPollInterval(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName string
+		Jitter   float64
+		Expected []time.Duration
+	}{
+		{
+			TestName: "no jitter",
+			Jitter:   0,
+			Expected: []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 60 * time.Second, 60 * time.Second},
+		},
+		{
+			TestName: "half jitter",
+			Jitter:   0.5,
+			Expected: []time.Duration{9 * time.Second, 18 * time.Second, 36 * time.Second, 54 * time.Second, 54 * time.Second},
+		},
+		{
+			TestName: "full jitter",
+			Jitter:   1,
+			Expected: []time.Duration{8 * time.Second, 16 * time.Second, 32 * time.Second, 48 * time.Second, 48 * time.Second},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			for attempt, expected := range testCase.Expected {
+				if got := tfnetworkmanager.CoreNetworkUpdatedPollInterval(attempt, testCase.Jitter); got != expected {
+					t.Errorf("attempt %d: got %s, expected %s", attempt, got, expected)
+				}
+			}
+
+			if got, expected := tfnetworkmanager.CoreNetworkUpdatedPollInterval(1000, testCase.Jitter), testCase.Expected[len(testCase.Expected)-1]; got != expected {
+				t.Errorf("attempt 1000: got %s, expected %s", got, expected)
+			}
+		})
+	}
+}
+
+func TestCoreNetworkPolicyErrorsDetail(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName     string
+		PolicyErrors []*networkmanager.CoreNetworkPolicyError
+		Expected     string
+	}{
+		{
+			TestName: "no errors",
+			Expected: "",
+		},
+		{
+			TestName: "errors",
+			PolicyErrors: []*networkmanager.CoreNetworkPolicyError{
+				{
+					ErrorCode: aws.String("INVALID_SEGMENT_NAME"),
+					Message:   aws.String("Segment name is not valid"),
+					Path:      aws.String("$.segments[0].name"),
+				},
+				nil,
+				{
+					ErrorCode: aws.String("MISSING_EDGE_LOCATIONS"),
+					Message:   aws.String("At least one edge location is required"),
+				},
+			},
+			Expected: "- INVALID_SEGMENT_NAME: Segment name is not valid (path: $.segments[0].name)\n" +
+				"- MISSING_EDGE_LOCATIONS: At least one edge location is required",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfnetworkmanager.CoreNetworkPolicyErrorsDetail(testCase.PolicyErrors); got != testCase.Expected {
+				t.Errorf("got %q, expected %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestCoreNetworkPolicyExecutionError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName    string
+		State       string
+		ExpectError bool
+	}{
+		{
+			TestName: "executed",
+			State:    networkmanager.ChangeSetStateExecutionSucceeded,
+		},
+		{
+			TestName:    "late failure",
+			State:       networkmanager.ChangeSetStateFailedGeneration,
+			ExpectError: true,
+		},
+		{
+			TestName:    "still executing",
+			State:       networkmanager.ChangeSetStateExecuting,
+			ExpectError: true,
+		},
+		{
+			TestName:    "superseded",
+			State:       networkmanager.ChangeSetStateOutOfDate,
+			ExpectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			err := tfnetworkmanager.CoreNetworkPolicyExecutionError(&networkmanager.CoreNetworkPolicy{
+				ChangeSetState:  aws.String(testCase.State),
+				PolicyVersionId: aws.Int64(2),
+			})
+
+			if got, want := err != nil, testCase.ExpectError; got != want {
+				t.Errorf("got error %v, expected error %t", err, want)
+			}
+		})
+	}
+}
+
+func TestCoreNetworkPolicyVersionExecutable(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName string
+		Alias    string
+		State    string
+		Expected bool
+	}{
+		{
+			TestName: "latest ready",
+			Alias:    networkmanager.CoreNetworkPolicyAliasLatest,
+			State:    networkmanager.ChangeSetStateReadyToExecute,
+			Expected: true,
+		},
+		{
+			TestName: "latest generating",
+			Alias:    networkmanager.CoreNetworkPolicyAliasLatest,
+			State:    networkmanager.ChangeSetStatePendingGeneration,
+			Expected: true,
+		},
+		{
+			TestName: "latest executed",
+			Alias:    networkmanager.CoreNetworkPolicyAliasLatest,
+			State:    networkmanager.ChangeSetStateExecutionSucceeded,
+		},
+		{
+			TestName: "live",
+			Alias:    networkmanager.CoreNetworkPolicyAliasLive,
+			State:    networkmanager.ChangeSetStateExecutionSucceeded,
+		},
+		{
+			TestName: "older version",
+			State:    networkmanager.ChangeSetStateOutOfDate,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			policy := &networkmanager.CoreNetworkPolicy{
+				ChangeSetState:  aws.String(testCase.State),
+				PolicyVersionId: aws.Int64(2),
+			}
+
+			if testCase.Alias != "" {
+				policy.Alias = aws.String(testCase.Alias)
+			}
+
+			if got, want := tfnetworkmanager.CoreNetworkPolicyVersionExecutable(policy), testCase.Expected; got != want {
+				t.Errorf("got %t, expected %t", got, want)
+			}
+		})
+	}
+}
+
+func TestRetryCoreNetworkPolicyConflict(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	output, err := tfnetworkmanager.RetryCoreNetworkPolicyConflict(context.Background(), 30*time.Second, func() (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, fmt.Errorf("putting Network Manager Core Network (core-network-0123456789abcdef0) policy: %w", awserr.New(networkmanager.ErrCodeConflictException, "change set in progress", nil))
+		}
+		return &networkmanager.CoreNetworkPolicy{PolicyVersionId: aws.Int64(2)}, nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got, want := aws.Int64Value(output.(*networkmanager.CoreNetworkPolicy).PolicyVersionId), int64(2); got != want {
+		t.Errorf("got policy version %d, expected %d", got, want)
+	}
+
+	if calls != 2 {
+		t.Errorf("got %d calls, expected 2", calls)
+	}
+}
+
+func TestCoreNetworkPolicyDocumentHash(t *testing.T) {
+	t.Parallel()
+
+	hash, err := tfnetworkmanager.CoreNetworkPolicyDocumentHash(`{"version":"2021.12","segments":[{"name":"one"}]}`)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got, want := len(hash), 64; got != want {
+		t.Errorf("got hash %q of length %d, expected length %d", hash, got, want)
+	}
+
+	equivalent, err := tfnetworkmanager.CoreNetworkPolicyDocumentHash(`{
+  "segments": [{"name": "one"}],
+  "version": "2021.12"
+}`)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if equivalent != hash {
+		t.Errorf("got hash %q for equivalent document, expected %q", equivalent, hash)
+	}
+
+	different, err := tfnetworkmanager.CoreNetworkPolicyDocumentHash(`{"version":"2021.12","segments":[{"name":"two"}]}`)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if different == hash {
+		t.Errorf("got the same hash %q for a different document", hash)
+	}
+
+	withDefaults, err := tfnetworkmanager.CoreNetworkPolicyDocumentHash(`{"version":"2021.12","segments":[{"name":"one","isolate-attachments":false,"require-attachment-acceptance":true}]}`)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if withDefaults != hash {
+		t.Errorf("got hash %q for the document with AWS-injected defaults, expected %q", withDefaults, hash)
+	}
+
+	if _, err := tfnetworkmanager.CoreNetworkPolicyDocumentHash(`{`); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestSuppressEquivalentCoreNetworkPolicyDiffs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName string
+		Old      string
+		New      string
+		Expected bool
+	}{
+		{
+			TestName: "formatting",
+			Old:      `{"version":"2021.12","segments":[{"name":"one"}]}`,
+			New: `{
+  "segments": [{"name": "one"}],
+  "version": "2021.12"
+}`,
+			Expected: true,
+		},
+		{
+			TestName: "injected defaults",
+			Old:      `{"core-network-configuration":{"asn-ranges":["64512-64555"],"edge-locations":[{"location":"us-west-2"}],"vpn-ecmp-support":true},"segments":[{"isolate-attachments":false,"name":"one","require-attachment-acceptance":true}],"version":"2021.12"}`,
+			New:      `{"version":"2021.12","core-network-configuration":{"asn-ranges":["64512-64555"],"edge-locations":[{"location":"us-west-2"}]},"segments":[{"name":"one"}]}`,
+			Expected: true,
+		},
+		{
+			TestName: "configured non-default",
+			Old:      `{"core-network-configuration":{"asn-ranges":["64512-64555"],"vpn-ecmp-support":true},"version":"2021.12"}`,
+			New:      `{"version":"2021.12","core-network-configuration":{"asn-ranges":["64512-64555"],"vpn-ecmp-support":false}}`,
+			Expected: false,
+		},
+		{
+			TestName: "live non-default",
+			Old:      `{"segments":[{"isolate-attachments":true,"name":"one","require-attachment-acceptance":true}],"version":"2021.12"}`,
+			New:      `{"version":"2021.12","segments":[{"name":"one"}]}`,
+			Expected: false,
+		},
+		{
+			TestName: "content",
+			Old:      `{"segments":[{"isolate-attachments":false,"name":"one","require-attachment-acceptance":true}],"version":"2021.12"}`,
+			New:      `{"version":"2021.12","segments":[{"name":"two"}]}`,
+			Expected: false,
+		},
+		{
+			TestName: "empty",
+			New:      `{"version":"2021.12","segments":[{"name":"one"}]}`,
+			Expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfnetworkmanager.SuppressEquivalentCoreNetworkPolicyDiffs("policy_document", testCase.Old, testCase.New, nil); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestWriteCoreNetworkPolicyFile(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "policy.json")
+	policyDocument := `{"version":"2021.12"}`
+
+	for i, want := range []bool{true, false} {
+		written, err := tfnetworkmanager.WriteCoreNetworkPolicyFile(filename, policyDocument)
+
+		if err != nil {
+			t.Fatalf("write %d: unexpected error: %s", i, err)
+		}
+
+		if written != want {
+			t.Errorf("write %d: got written %t, expected %t", i, written, want)
+		}
+	}
+
+	policyDocument = `{"version":"2021.12","segments":[{"name":"one"}]}`
+
+	written, err := tfnetworkmanager.WriteCoreNetworkPolicyFile(filename, policyDocument)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !written {
+		t.Error("changed policy document was not written")
+	}
+
+	if got, err := os.ReadFile(filename); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if string(got) != policyDocument {
+		t.Errorf("got %s, expected %s", got, policyDocument)
+	}
+}
+
+func TestMergeCoreNetworkPolicyOverrides(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName      string
+		Base          string
+		Overrides     string
+		Expected      string
+		ExpectedError bool
+	}{
+		{
+			TestName: "no overrides",
+			Base:     `{"version":"2021.12","segments":[{"name":"one"}]}`,
+			Expected: `{"version":"2021.12","segments":[{"name":"one"}]}`,
+		},
+		{
+			TestName:  "nested objects merged",
+			Base:      `{"version":"2021.12","core-network-configuration":{"asn-ranges":["64512-64555"],"vpn-ecmp-support":false}}`,
+			Overrides: `{"core-network-configuration":{"vpn-ecmp-support":true}}`,
+			Expected:  `{"core-network-configuration":{"asn-ranges":["64512-64555"],"vpn-ecmp-support":true},"version":"2021.12"}`,
+		},
+		{
+			TestName:  "arrays replaced",
+			Base:      `{"version":"2021.12","segments":[{"name":"one"},{"name":"two"}]}`,
+			Overrides: `{"segments":[{"name":"three"}]}`,
+			Expected:  `{"segments":[{"name":"three"}],"version":"2021.12"}`,
+		},
+		{
+			TestName:  "null removes key",
+			Base:      `{"version":"2021.12","attachment-policies":[]}`,
+			Overrides: `{"attachment-policies":null}`,
+			Expected:  `{"version":"2021.12"}`,
+		},
+		{
+			TestName:      "overrides not an object",
+			Base:          `{"version":"2021.12"}`,
+			Overrides:     `["version"]`,
+			ExpectedError: true,
+		},
+		{
+			TestName:      "invalid base",
+			Base:          `{`,
+			Overrides:     `{"version":"2021.12"}`,
+			ExpectedError: true,
+		},
+		{
+			TestName:      "invalid overrides",
+			Base:          `{"version":"2021.12"}`,
+			Overrides:     `{`,
+			ExpectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tfnetworkmanager.MergeCoreNetworkPolicyOverrides(testCase.Base, testCase.Overrides)
+
+			if testCase.ExpectedError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestCoreNetworkPolicyClientToken(t *testing.T) {
+	t.Parallel()
+
+	const coreNetworkID = "core-network-0123456789abcdef0"
+
+	token, err := tfnetworkmanager.CoreNetworkPolicyClientToken(coreNetworkID, 1, `{"version":"2021.12","segments":[{"name":"one"}]}`)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	equivalent, err := tfnetworkmanager.CoreNetworkPolicyClientToken(coreNetworkID, 1, `{"segments": [{"name": "one"}], "version": "2021.12"}`)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if equivalent != token {
+		t.Errorf("got token %q for equivalent document, expected %q", equivalent, token)
+	}
+
+	for _, testCase := range []struct {
+		TestName                string
+		CoreNetworkID           string
+		PreviousPolicyVersionID int64
+		PolicyDocument          string
+	}{
+		{
+			TestName:                "different document",
+			CoreNetworkID:           coreNetworkID,
+			PreviousPolicyVersionID: 1,
+			PolicyDocument:          `{"version":"2021.12","segments":[{"name":"two"}]}`,
+		},
+		{
+			TestName:                "different previous version",
+			CoreNetworkID:           coreNetworkID,
+			PreviousPolicyVersionID: 2,
+			PolicyDocument:          `{"version":"2021.12","segments":[{"name":"one"}]}`,
+		},
+		{
+			TestName:                "different core network",
+			CoreNetworkID:           "core-network-0fedcba9876543210",
+			PreviousPolicyVersionID: 1,
+			PolicyDocument:          `{"version":"2021.12","segments":[{"name":"one"}]}`,
+		},
+	} {
+		different, err := tfnetworkmanager.CoreNetworkPolicyClientToken(testCase.CoreNetworkID, testCase.PreviousPolicyVersionID, testCase.PolicyDocument)
+
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", testCase.TestName, err)
+		}
+
+		if different == token {
+			t.Errorf("%s: got the same token %q", testCase.TestName, token)
+		}
+	}
+
+	if _, err := tfnetworkmanager.CoreNetworkPolicyClientToken(coreNetworkID, 1, `{`); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestCoreNetworkRolledBackDiags(t *testing.T) {
+	t.Parallel()
+
+	diags := diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  "waiting for Network Manager Core Network (core-network-0123456789abcdef0) update: unexpected state 'FAILED'",
+			Detail:   "Policy version 3 reported the following errors:",
+		},
+		{
+			Severity: diag.Warning,
+			Summary:  "warning",
+		},
+	}
+
+	got := tfnetworkmanager.CoreNetworkRolledBackDiags(diags, 2)
+
+	if want := "waiting for Network Manager Core Network (core-network-0123456789abcdef0) update: unexpected state 'FAILED', rolled back to version 2"; got[0].Summary != want {
+		t.Errorf("got summary %q, expected %q", got[0].Summary, want)
+	}
+
+	if got[0].Detail != diags[0].Detail {
+		t.Errorf("got detail %q, expected %q", got[0].Detail, diags[0].Detail)
+	}
+
+	if got[1].Summary != "warning" {
+		t.Errorf("got warning summary %q, expected %q", got[1].Summary, "warning")
+	}
+
+	if diags[0].Summary == got[0].Summary {
+		t.Error("expected the original diagnostics to be unchanged")
+	}
+}
+
+func TestCoreNetworkPolicyValidationDiags(t *testing.T) {
+	t.Parallel()
+
+	const id = "core-network-0123456789abcdef0"
+
+	testCases := []struct {
+		TestName         string
+		Policy           *networkmanager.CoreNetworkPolicy
+		Err              error
+		ExpectedSeverity diag.Severity
+		ExpectedSummary  string
+		ExpectedDetail   string
+	}{
+		{
+			TestName:         "validated",
+			Policy:           &networkmanager.CoreNetworkPolicy{ChangeSetState: aws.String(networkmanager.ChangeSetStateReadyToExecute)},
+			ExpectedSeverity: diag.Warning,
+			ExpectedSummary:  "Network Manager Core Network (core-network-0123456789abcdef0) policy version (3) validated but not executed",
+		},
+		{
+			TestName: "policy errors",
+			Policy: &networkmanager.CoreNetworkPolicy{
+				ChangeSetState: aws.String(networkmanager.ChangeSetStateFailedGeneration),
+				PolicyErrors: []*networkmanager.CoreNetworkPolicyError{
+					{ErrorCode: aws.String("INVALID_SEGMENT"), Message: aws.String("segment is invalid"), Path: aws.String("segments[0]")},
+				},
+			},
+			Err:              errors.New("unexpected state 'FAILED_GENERATION'"),
+			ExpectedSeverity: diag.Error,
+			ExpectedSummary:  "validating Network Manager Core Network (core-network-0123456789abcdef0) policy version (3)",
+			ExpectedDetail:   "Policy version 3 reported the following errors:\n- INVALID_SEGMENT: segment is invalid (path: segments[0])",
+		},
+		{
+			TestName:         "no policy errors",
+			Err:              errors.New("unexpected state 'FAILED_GENERATION'"),
+			ExpectedSeverity: diag.Error,
+			ExpectedSummary:  "waiting for Network Manager Core Network (core-network-0123456789abcdef0) change set (3) generation: unexpected state 'FAILED_GENERATION'",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got := tfnetworkmanager.CoreNetworkPolicyValidationDiags(id, 3, testCase.Policy, testCase.Err)
+
+			if len(got) != 1 {
+				t.Fatalf("got %d diagnostics, expected 1", len(got))
+			}
+
+			if got[0].Severity != testCase.ExpectedSeverity {
+				t.Errorf("got severity %v, expected %v", got[0].Severity, testCase.ExpectedSeverity)
+			}
+
+			if got[0].Summary != testCase.ExpectedSummary {
+				t.Errorf("got summary %q, expected %q", got[0].Summary, testCase.ExpectedSummary)
+			}
+
+			if testCase.ExpectedDetail != "" && got[0].Detail != testCase.ExpectedDetail {
+				t.Errorf("got detail %q, expected %q", got[0].Detail, testCase.ExpectedDetail)
+			}
+		})
+	}
+}
+
+func TestCoreNetworkPolicyHasStagedChanges(t *testing.T) {
+	t.Parallel()
+
+	live := &networkmanager.CoreNetworkPolicy{
+		ChangeSetState:  aws.String(networkmanager.ChangeSetStateExecutionSucceeded),
+		PolicyDocument:  aws.JSONValue{"segments": []interface{}{map[string]interface{}{"name": "one"}}},
+		PolicyVersionId: aws.Int64(1),
+	}
+
+	testCases := []struct {
+		TestName string
+		Latest   *networkmanager.CoreNetworkPolicy
+		Expected bool
+	}{
+		{
+			TestName: "no latest",
+		},
+		{
+			TestName: "latest is live",
+			Latest:   live,
+		},
+		{
+			TestName: "staged",
+			Latest: &networkmanager.CoreNetworkPolicy{
+				ChangeSetState:  aws.String(networkmanager.ChangeSetStateReadyToExecute),
+				PolicyDocument:  aws.JSONValue{"segments": []interface{}{map[string]interface{}{"name": "two"}}},
+				PolicyVersionId: aws.Int64(2),
+			},
+			Expected: true,
+		},
+		{
+			TestName: "staged same document",
+			Latest: &networkmanager.CoreNetworkPolicy{
+				ChangeSetState:  aws.String(networkmanager.ChangeSetStateReadyToExecute),
+				PolicyDocument:  aws.JSONValue{"segments": []interface{}{map[string]interface{}{"name": "one"}}},
+				PolicyVersionId: aws.Int64(2),
+			},
+		},
+		{
+			TestName: "executed",
+			Latest: &networkmanager.CoreNetworkPolicy{
+				ChangeSetState:  aws.String(networkmanager.ChangeSetStateExecutionSucceeded),
+				PolicyDocument:  aws.JSONValue{"segments": []interface{}{map[string]interface{}{"name": "two"}}},
+				PolicyVersionId: aws.Int64(2),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tfnetworkmanager.CoreNetworkPolicyHasStagedChanges(live, testCase.Latest); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestCoreNetworkPolicyDocumentRoundTripError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName    string
+		Document    aws.JSONValue
+		ExpectError bool
+	}{
+		{
+			TestName: "empty",
+			Document: aws.JSONValue{},
+		},
+		{
+			TestName: "special characters",
+			Document: aws.JSONValue{
+				"version": "2021.12",
+				"core-network-configuration": map[string]interface{}{
+					"asn-ranges": []interface{}{"64512-65534"},
+					"edge-locations": []interface{}{
+						map[string]interface{}{"location": "us-west-2", "asn": float64(64555)},
+					},
+				},
+				"segments": []interface{}{
+					map[string]interface{}{
+						"name":        "segment",
+						"description": "<html> & \"quotes\" \\ back\\slash / slash \t tab \n newline \u2028\u2029 ünïcödé 日本 🚀",
+					},
+				},
+			},
+		},
+		{
+			TestName: "invalid UTF-8",
+			Document: aws.JSONValue{
+				"segments": []interface{}{
+					map[string]interface{}{"name": "segment", "description": "\xff"},
+				},
+			},
+			ExpectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			encoded, err := protocol.EncodeJSONValue(testCase.Document, protocol.NoEscape)
+
+			if err != nil {
+				t.Fatalf("encoding policy document: %s", err)
+			}
+
+			err = tfnetworkmanager.CoreNetworkPolicyDocumentRoundTripError(testCase.Document, encoded)
+
+			if err == nil && testCase.ExpectError {
+				t.Fatalf("expected error")
+			}
+
+			if err != nil && !testCase.ExpectError {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestFlattenCoreNetworkPolicyDocument(t *testing.T) {
+	t.Parallel()
+
+	expected := `{"core-network-configuration":{"asn-ranges":["64512-65534"],"edge-locations":[{"location":"us-east-1"}]},"segments":[{"description":"base-policy","name":"segment"}],"version":"2021.12"}`
+
+	testCases := []struct {
+		TestName string
+		Document aws.JSONValue
+	}{
+		{
+			TestName: "sorted keys",
+			Document: aws.JSONValue{
+				"core-network-configuration": map[string]interface{}{
+					"asn-ranges":     []interface{}{"64512-65534"},
+					"edge-locations": []interface{}{map[string]interface{}{"location": "us-east-1"}},
+				},
+				"segments": []interface{}{map[string]interface{}{"description": "base-policy", "name": "segment"}},
+				"version":  "2021.12",
+			},
+		},
+		{
+			TestName: "reordered keys",
+			Document: aws.JSONValue{
+				"version":                    "2021.12",
+				"segments":                   json.RawMessage(`[{"name":"segment","description":"base-policy"}]`),
+				"core-network-configuration": json.RawMessage(`{"edge-locations":[{"location":"us-east-1"}],"asn-ranges":["64512-65534"]}`),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tfnetworkmanager.FlattenCoreNetworkPolicyDocument(testCase.Document)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != expected {
+				t.Errorf("got %s, expected %s", got, expected)
+			}
+		})
+	}
+}
+
+func TestCoreNetworkPolicyOrphanedAttachments(t *testing.T) {
+	t.Parallel()
+
+	policyDocument := `{"core-network-configuration":{"asn-ranges":["65022-65534"],"edge-locations":[{"location":"us-east-1"},{"location":"us-west-2"}]},"segments":[{"name":"segment"}],"version":"2021.12"}`
+
+	testCases := []struct {
+		TestName    string
+		Attachments []*networkmanager.Attachment
+		Expected    []string
+	}{
+		{
+			TestName: "no attachments",
+		},
+		{
+			TestName: "all covered",
+			Attachments: []*networkmanager.Attachment{
+				{AttachmentId: aws.String("attachment-1"), EdgeLocation: aws.String("us-east-1"), State: aws.String(networkmanager.AttachmentStateAvailable)},
+				{AttachmentId: aws.String("attachment-2"), EdgeLocation: aws.String("us-west-2"), State: aws.String(networkmanager.AttachmentStateAvailable)},
+			},
+		},
+		{
+			TestName: "orphaned",
+			Attachments: []*networkmanager.Attachment{
+				{AttachmentId: aws.String("attachment-3"), EdgeLocation: aws.String("eu-west-1"), State: aws.String(networkmanager.AttachmentStateAvailable)},
+				{AttachmentId: aws.String("attachment-1"), EdgeLocation: aws.String("us-east-1"), State: aws.String(networkmanager.AttachmentStateAvailable)},
+				{AttachmentId: aws.String("attachment-2"), EdgeLocation: aws.String("ap-south-1"), State: aws.String(networkmanager.AttachmentStatePendingAttachmentAcceptance)},
 			},
-			{
-				Config: testAccCoreNetworkPolicyAttachmentConfig_basic(segmentValue),
-				Check: resource.ComposeTestCheckFunc(
-					testAccCheckCoreNetworkPolicyAttachmentExists(ctx, resourceName),
-					resource.TestCheckResourceAttr(resourceName, "policy_document", fmt.Sprintf("{\"core-network-configuration\":{\"asn-ranges\":[\"65022-65534\"],\"edge-locations\":[{\"location\":\"%s\"}],\"vpn-ecmp-support\":true},\"segments\":[{\"isolate-attachments\":false,\"name\":\"%s\",\"require-attachment-acceptance\":true}],\"version\":\"2021.12\"}", acctest.Region(), segmentValue)),
-					resource.TestCheckResourceAttrPair(resourceName, "core_network_id", "aws_networkmanager_core_network.test", "id"),
-					resource.TestCheckResourceAttrPair(resourceName, "id", "aws_networkmanager_core_network.test", "id"),
-					resource.TestCheckResourceAttr(resourceName, "state", networkmanager.CoreNetworkStateAvailable),
-				),
+			Expected: []string{"attachment-2 (ap-south-1)", "attachment-3 (eu-west-1)"},
+		},
+		{
+			TestName: "deleting ignored",
+			Attachments: []*networkmanager.Attachment{
+				{AttachmentId: aws.String("attachment-4"), EdgeLocation: aws.String("eu-west-1"), State: aws.String(networkmanager.AttachmentStateDeleting)},
 			},
 		},
-	})
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tfnetworkmanager.CoreNetworkPolicyOrphanedAttachments(policyDocument, testCase.Attachments)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got, want := strings.Join(got, ","), strings.Join(testCase.Expected, ","); got != want {
+				t.Errorf("got %q, expected %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCoreNetworkOrphanedAttachmentsWarning(t *testing.T) {
+	t.Parallel()
+
+	if diags := tfnetworkmanager.CoreNetworkOrphanedAttachmentsWarning("core-network-01234567890abcdef", nil); diags != nil {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+
+	diags := tfnetworkmanager.CoreNetworkOrphanedAttachmentsWarning("core-network-01234567890abcdef", []string{"attachment-1 (us-west-2)", "attachment-2 (us-west-2)"})
+
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+	}
+
+	if diags.HasError() {
+		t.Errorf("expected a warning, got %v", diags)
+	}
+
+	if detail := diags[0].Detail; !strings.Contains(detail, "attachment-1 (us-west-2), attachment-2 (us-west-2)") {
+		t.Errorf("detail %q does not name the orphaned attachments", detail)
+	}
 }
 
 func testAccCheckCoreNetworkPolicyAttachmentDestroy(ctx context.Context) resource.TestCheckFunc {
@@ -121,6 +1362,150 @@ func testAccCheckCoreNetworkPolicyAttachmentExists(ctx context.Context, n string
 	}
 }
 
+// testAccCheckCoreNetworkPolicyAttachmentDryRunNotExecuted checks that the LIVE policy still contains the segment
+// and that the LATEST policy version generated by the destroy dry run was not executed.
+func testAccCheckCoreNetworkPolicyAttachmentDryRunNotExecuted(ctx context.Context, n, segmentValue string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).NetworkManagerConn()
+
+		live, err := tfnetworkmanager.FindCoreNetworkPolicyByAlias(ctx, conn, rs.Primary.ID, networkmanager.CoreNetworkPolicyAliasLive)
+
+		if err != nil {
+			return err
+		}
+
+		document, err := protocol.EncodeJSONValue(live.PolicyDocument, protocol.NoEscape)
+
+		if err != nil {
+			return err
+		}
+
+		if !strings.Contains(document, segmentValue) {
+			return fmt.Errorf("Network Manager Core Network (%s) LIVE policy was reverted: %s", rs.Primary.ID, document)
+		}
+
+		latest, err := tfnetworkmanager.FindCoreNetworkPolicyByAlias(ctx, conn, rs.Primary.ID, networkmanager.CoreNetworkPolicyAliasLatest)
+
+		if err != nil {
+			return err
+		}
+
+		if state := aws.StringValue(latest.ChangeSetState); state != networkmanager.ChangeSetStateReadyToExecute {
+			return fmt.Errorf("Network Manager Core Network (%s) LATEST policy change set state is %s, expected %s", rs.Primary.ID, state, networkmanager.ChangeSetStateReadyToExecute)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckCoreNetworkPolicyAttachmentReverted checks that the LIVE policy is the executed base policy.
+func testAccCheckCoreNetworkPolicyAttachmentReverted(ctx context.Context, n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).NetworkManagerConn()
+
+		live, err := tfnetworkmanager.FindCoreNetworkPolicyByAlias(ctx, conn, rs.Primary.ID, networkmanager.CoreNetworkPolicyAliasLive)
+
+		if err != nil {
+			return err
+		}
+
+		document, err := protocol.EncodeJSONValue(live.PolicyDocument, protocol.NoEscape)
+
+		if err != nil {
+			return err
+		}
+
+		if !strings.Contains(document, "base-policy") {
+			return fmt.Errorf("Network Manager Core Network (%s) LIVE policy was not reverted: %s", rs.Primary.ID, document)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckCoreNetworkPolicyAttachmentWaitUpdated waits for a policy executed without waiting to finish.
+func testAccCheckCoreNetworkPolicyAttachmentWaitUpdated(ctx context.Context, n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).NetworkManagerConn()
+
+		_, err := tfnetworkmanager.WaitCoreNetworkUpdated(ctx, conn, rs.Primary.ID, 30*time.Minute)
+
+		return err
+	}
+}
+
+func testAccCoreNetworkPolicyAttachmentWritePolicyFile(t *testing.T, filename, segmentValue string) {
+	t.Helper()
+
+	policyDocument := fmt.Sprintf(`{"core-network-configuration":{"asn-ranges":["65022-65534"],"edge-locations":[{"location":%[1]q}]},"segments":[{"name":%[2]q}],"version":"2021.12"}`, acctest.Region(), segmentValue)
+
+	if err := os.WriteFile(filename, []byte(policyDocument), 0600); err != nil {
+		t.Fatalf("writing policy document file: %s", err)
+	}
+}
+
+// testAccCheckCoreNetworkPolicyAttachmentStagePolicy puts a new LATEST policy version without executing it.
+func testAccCheckCoreNetworkPolicyAttachmentStagePolicy(ctx context.Context, n, segmentValue string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).NetworkManagerConn()
+
+		policyDocument, err := protocol.DecodeJSONValue(fmt.Sprintf(`{"core-network-configuration":{"asn-ranges":["65022-65534"],"edge-locations":[{"location":%[1]q}]},"segments":[{"name":%[2]q}],"version":"2021.12"}`, acctest.Region(), segmentValue), protocol.NoEscape)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = conn.PutCoreNetworkPolicyWithContext(ctx, &networkmanager.PutCoreNetworkPolicyInput{
+			CoreNetworkId:  aws.String(rs.Primary.ID),
+			PolicyDocument: policyDocument,
+		})
+
+		return err
+	}
+}
+
+// testAccCheckCoreNetworkPolicyAttachmentExecutePolicy puts and executes a new policy version out-of-band.
+func testAccCheckCoreNetworkPolicyAttachmentExecutePolicy(ctx context.Context, n, segmentValue string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := acctest.Provider.Meta().(*conns.AWSClient).NetworkManagerConn()
+
+		policyDocument := fmt.Sprintf(`{"core-network-configuration":{"asn-ranges":["65022-65534"],"edge-locations":[{"location":%[1]q}]},"segments":[{"name":%[2]q}],"version":"2021.12"}`, acctest.Region(), segmentValue)
+
+		if _, err := tfnetworkmanager.PutAndExecuteCoreNetworkPolicy(ctx, conn, rs.Primary.ID, policyDocument, "", 30*time.Minute); err != nil {
+			return err
+		}
+
+		_, err := tfnetworkmanager.WaitCoreNetworkUpdated(ctx, conn, rs.Primary.ID, 30*time.Minute)
+
+		return err
+	}
+}
+
 func testAccCoreNetworkPolicyAttachmentConfig_basic(segmentValue string) string {
 	return fmt.Sprintf(`
 resource "aws_networkmanager_global_network" "test" {}
@@ -150,6 +1535,162 @@ resource "aws_networkmanager_core_network_policy_attachment" "test" {
 `, segmentValue, acctest.Region())
 }
 
+func testAccCoreNetworkPolicyAttachmentConfig_injectedDefaults(segmentValue string) string {
+	return fmt.Sprintf(`
+resource "aws_networkmanager_global_network" "test" {}
+
+resource "aws_networkmanager_core_network" "test" {
+  global_network_id = aws_networkmanager_global_network.test.id
+}
+
+resource "aws_networkmanager_core_network_policy_attachment" "test" {
+  core_network_id = aws_networkmanager_core_network.test.id
+  policy_document = jsonencode({
+    version = "2021.12"
+    core-network-configuration = {
+      asn-ranges = ["65022-65534"]
+      edge-locations = [{
+        location = %[2]q
+      }]
+    }
+    segments = [{
+      name = %[1]q
+    }]
+  })
+}
+`, segmentValue, acctest.Region())
+}
+
+func testAccCoreNetworkPolicyAttachmentConfig_destroyDryRun(segmentValue string) string {
+	return fmt.Sprintf(`
+resource "aws_networkmanager_global_network" "test" {}
+
+data "aws_networkmanager_core_network_policy_document" "test" {
+  core_network_configuration {
+    asn_ranges = ["65022-65534"]
+
+    edge_locations {
+      location = %[2]q
+    }
+  }
+
+  segments {
+    name = %[1]q
+  }
+}
+
+resource "aws_networkmanager_core_network" "test" {
+  global_network_id = aws_networkmanager_global_network.test.id
+}
+
+resource "aws_networkmanager_core_network_policy_attachment" "test" {
+  core_network_id = aws_networkmanager_core_network.test.id
+  policy_document = data.aws_networkmanager_core_network_policy_document.test.json
+  destroy_dry_run = true
+}
+`, segmentValue, acctest.Region())
+}
+
+func testAccCoreNetworkPolicyAttachmentConfig_revertOnDestroy(segmentValue string) string {
+	return fmt.Sprintf(`
+resource "aws_networkmanager_global_network" "test" {}
+
+data "aws_networkmanager_core_network_policy_document" "test" {
+  core_network_configuration {
+    asn_ranges = ["65022-65534"]
+
+    edge_locations {
+      location = %[2]q
+    }
+  }
+
+  segments {
+    name = %[1]q
+  }
+}
+
+resource "aws_networkmanager_core_network" "test" {
+  global_network_id = aws_networkmanager_global_network.test.id
+}
+
+resource "aws_networkmanager_core_network_policy_attachment" "test" {
+  core_network_id   = aws_networkmanager_core_network.test.id
+  policy_document   = data.aws_networkmanager_core_network_policy_document.test.json
+  revert_on_destroy = true
+}
+`, segmentValue, acctest.Region())
+}
+
+func testAccCoreNetworkPolicyAttachmentConfig_waitForExecution(segmentValue string) string {
+	return fmt.Sprintf(`
+resource "aws_networkmanager_global_network" "test" {}
+
+data "aws_networkmanager_core_network_policy_document" "test" {
+  core_network_configuration {
+    asn_ranges = ["65022-65534"]
+
+    edge_locations {
+      location = %[2]q
+    }
+  }
+
+  segments {
+    name = %[1]q
+  }
+}
+
+resource "aws_networkmanager_core_network" "test" {
+  global_network_id = aws_networkmanager_global_network.test.id
+}
+
+resource "aws_networkmanager_core_network_policy_attachment" "test" {
+  core_network_id    = aws_networkmanager_core_network.test.id
+  policy_document    = data.aws_networkmanager_core_network_policy_document.test.json
+  wait_for_execution = false
+}
+`, segmentValue, acctest.Region())
+}
+
+func testAccCoreNetworkPolicyAttachmentConfig_sourceFile(filename string) string {
+	return fmt.Sprintf(`
+resource "aws_networkmanager_global_network" "test" {}
+
+resource "aws_networkmanager_core_network" "test" {
+  global_network_id = aws_networkmanager_global_network.test.id
+}
+
+resource "aws_networkmanager_core_network_policy_attachment" "test" {
+  core_network_id = aws_networkmanager_core_network.test.id
+  source_file     = %[1]q
+}
+`, filename)
+}
+
+func testAccCoreNetworkPolicyAttachmentConfig_coreNetworkOnly() string {
+	return `
+resource "aws_networkmanager_global_network" "test" {}
+
+resource "aws_networkmanager_core_network" "test" {
+  global_network_id = aws_networkmanager_global_network.test.id
+}
+`
+}
+
+func testAccCoreNetworkPolicyAttachmentConfig_policyVersionID(policyVersionID int) string {
+	return fmt.Sprintf(`
+resource "aws_networkmanager_global_network" "test" {}
+
+resource "aws_networkmanager_core_network" "test" {
+  global_network_id = aws_networkmanager_global_network.test.id
+}
+
+resource "aws_networkmanager_core_network_policy_attachment" "test" {
+  core_network_id   = aws_networkmanager_core_network.test.id
+  policy_version_id = %[1]d
+}
+`, policyVersionID)
+}
+
 func testAccCoreNetworkPolicyAttachmentConfig_vpcAttachmentCreate() string {
 	return acctest.ConfigCompose(acctest.ConfigAvailableAZsNoOptIn(), fmt.Sprintf(`
 resource "aws_vpc" "test" {
@@ -0,0 +1,24 @@
+package networkmanager
+
+// Exports for use in tests only.
+var (
+	CoreNetworkChangeSetSegmentCount        = coreNetworkChangeSetSegmentCount
+	CoreNetworkChangeSetSummary             = coreNetworkChangeSetSummary
+	CoreNetworkPolicyDocumentRoundTripError = coreNetworkPolicyDocumentRoundTripError
+	CoreNetworkUpdatedPollInterval          = coreNetworkUpdatedPollInterval
+	CoreNetworkPolicyClientToken            = coreNetworkPolicyClientToken
+	CoreNetworkPolicyDocumentHash           = coreNetworkPolicyDocumentHash
+	CoreNetworkPolicyErrorsDetail           = coreNetworkPolicyErrorsDetail
+	CoreNetworkPolicyExecutionError         = coreNetworkPolicyExecutionError
+	CoreNetworkPolicyHasStagedChanges       = coreNetworkPolicyHasStagedChanges
+	CoreNetworkOrphanedAttachmentsWarning   = coreNetworkOrphanedAttachmentsWarning
+	CoreNetworkPolicyOrphanedAttachments    = coreNetworkPolicyOrphanedAttachments
+	CoreNetworkPolicyValidationDiags        = coreNetworkPolicyValidationDiags
+	CoreNetworkPolicyVersionExecutable      = coreNetworkPolicyVersionExecutable
+	CoreNetworkRolledBackDiags              = coreNetworkRolledBackDiags
+	FlattenCoreNetworkPolicyDocument        = flattenCoreNetworkPolicyDocument
+	MergeCoreNetworkPolicyOverrides         = mergeCoreNetworkPolicyOverrides
+	RetryCoreNetworkPolicyConflict          = retryCoreNetworkPolicyConflict
+	WaitCoreNetworkUpdated                  = waitCoreNetworkUpdated
+	WriteCoreNetworkPolicyFile              = writeCoreNetworkPolicyFile
+)
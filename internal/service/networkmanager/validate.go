@@ -0,0 +1,304 @@
+package networkmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// coreNetworkPolicyVersions are the core network policy document versions known to the provider.
+var coreNetworkPolicyVersions = []string{
+	"2021.12",
+}
+
+var coreNetworkPolicyVersionRegexp = regexp.MustCompile(`^\d{4}\.(0[1-9]|1[0-2])$`)
+
+// ValidateCoreNetworkPolicyDocument validates a core network policy document before it is submitted.
+func ValidateCoreNetworkPolicyDocument(v interface{}, k string) (ws []string, es []error) {
+	var doc map[string]interface{}
+
+	if err := json.Unmarshal([]byte(v.(string)), &doc); err != nil {
+		// StringIsJSON reports malformed documents.
+		return
+	}
+
+	warnings, errs := validCoreNetworkPolicyDocumentContent(doc)
+
+	for _, w := range warnings {
+		ws = append(ws, fmt.Sprintf("%q: %s", k, w))
+	}
+
+	for _, err := range errs {
+		es = append(es, fmt.Errorf("%q: %w", k, err))
+	}
+
+	return
+}
+
+// validCoreNetworkPolicyDocumentContent returns the warnings and errors of the checks made by ValidateCoreNetworkPolicyDocument.
+func validCoreNetworkPolicyDocumentContent(doc map[string]interface{}) ([]string, []error) {
+	var warnings []string
+	var errs []error
+
+	if version, ok := doc["version"]; ok {
+		w, err := validCoreNetworkPolicyVersion(version)
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		if w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+
+	errs = append(errs, validCoreNetworkPolicyShareActions(doc)...)
+
+	return warnings, errs
+}
+
+// validCoreNetworkPolicy makes every check of the policy document that is about to be submitted,
+// including a document read from source_file or merged with overrides_document, which no ValidateFunc sees.
+// The strict checks are only made when strict is true.
+func validCoreNetworkPolicy(document string, strict bool) ([]string, []error) {
+	if err := validCoreNetworkPolicyRequiredSections(document); err != nil {
+		return nil, []error{err}
+	}
+
+	var doc map[string]interface{}
+
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		return nil, []error{fmt.Errorf("policy document is not a JSON object: %w", err)}
+	}
+
+	warnings, errs := validCoreNetworkPolicyDocumentContent(doc)
+
+	if strict {
+		errs = append(errs, validCoreNetworkPolicyStrict(document)...)
+	}
+
+	return warnings, errs
+}
+
+// coreNetworkPolicyRequiredSections are the top-level sections every core network policy document must contain.
+var coreNetworkPolicyRequiredSections = []string{
+	"version",
+	"core-network-configuration",
+	"segments",
+}
+
+// validCoreNetworkPolicyRequiredSections returns an error naming any required top-level sections missing from the document.
+// It's a lightweight check made before a policy is submitted, leaving full schema validation to the API.
+func validCoreNetworkPolicyRequiredSections(document string) error {
+	var doc map[string]interface{}
+
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		return fmt.Errorf("policy document is not a JSON object: %w", err)
+	}
+
+	var missing []string
+
+	for _, section := range coreNetworkPolicyRequiredSections {
+		if v, ok := doc[section]; !ok || v == nil {
+			missing = append(missing, section)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("policy document is missing required sections: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// coreNetworkPolicySegmentNames returns the names of the segments declared in the document.
+func coreNetworkPolicySegmentNames(doc map[string]interface{}) map[string]bool {
+	segments := make(map[string]bool)
+
+	if v, ok := doc["segments"].([]interface{}); ok {
+		for _, v := range v {
+			if segment, ok := v.(map[string]interface{}); ok {
+				if name, ok := segment["name"].(string); ok {
+					segments[name] = true
+				}
+			}
+		}
+	}
+
+	return segments
+}
+
+// validCoreNetworkPolicyShareActions returns an error for each share segment action that
+// references an undefined segment or has an invalid share-with or mode.
+func validCoreNetworkPolicyShareActions(doc map[string]interface{}) []error {
+	segments := coreNetworkPolicySegmentNames(doc)
+
+	var errs []error
+
+	actions, _ := doc["segment-actions"].([]interface{})
+
+	for i, v := range actions {
+		action, ok := v.(map[string]interface{})
+
+		if !ok || action["action"] != "share" {
+			continue
+		}
+
+		if segment, _ := action["segment"].(string); segment == "" {
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: share action must specify a segment", i))
+		} else if !segments[segment] {
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: share action segment %q is not defined", i, segment))
+		}
+
+		if mode, ok := action["mode"]; ok && mode != "attachment-route" {
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: share action mode %q is not valid, expected %q", i, mode, "attachment-route"))
+		}
+
+		switch shareWith := action["share-with"].(type) {
+		case nil:
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: share action must specify share-with", i))
+		case string:
+			if shareWith != "*" {
+				errs = append(errs, fmt.Errorf("segment-actions[%d]: share-with %q is not valid, expected \"*\", a list of segments or an except object", i, shareWith))
+			}
+		case []interface{}:
+			errs = append(errs, validCoreNetworkPolicyShareWithSegments(i, "share-with", shareWith, segments)...)
+		case map[string]interface{}:
+			except, ok := shareWith["except"].([]interface{})
+
+			if !ok || len(except) == 0 || len(shareWith) != 1 {
+				errs = append(errs, fmt.Errorf("segment-actions[%d]: share-with object must only contain a non-empty except list", i))
+				continue
+			}
+
+			errs = append(errs, validCoreNetworkPolicyShareWithSegments(i, "share-with except", except, segments)...)
+		default:
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: share-with must be \"*\", a list of segments or an except object, got %T", i, shareWith))
+		}
+	}
+
+	return errs
+}
+
+func validCoreNetworkPolicyShareWithSegments(i int, field string, names []interface{}, segments map[string]bool) []error {
+	var errs []error
+
+	if len(names) == 0 {
+		errs = append(errs, fmt.Errorf("segment-actions[%d]: %s must not be empty", i, field))
+	}
+
+	for _, v := range names {
+		name, ok := v.(string)
+
+		if !ok {
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: %s must only contain segment names, got %T", i, field, v))
+			continue
+		}
+
+		if name != "*" && !segments[name] {
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: %s segment %q is not defined", i, field, name))
+		}
+	}
+
+	return errs
+}
+
+// coreNetworkPolicyRuleNumberMin and coreNetworkPolicyRuleNumberMax bound attachment policy rule numbers.
+const (
+	coreNetworkPolicyRuleNumberMin = 1
+	coreNetworkPolicyRuleNumberMax = 65535
+)
+
+// validCoreNetworkPolicyStrict returns an error for each segment action, other than share actions which are always checked,
+// that references an undefined segment and for each attachment policy without a valid, unique rule-number.
+// These checks are opt-in as they may reject documents that AWS accepts as the policy schema evolves.
+func validCoreNetworkPolicyStrict(document string) []error {
+	var doc map[string]interface{}
+
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		return []error{fmt.Errorf("policy document is not a JSON object: %w", err)}
+	}
+
+	segments := coreNetworkPolicySegmentNames(doc)
+
+	var errs []error
+
+	actions, _ := doc["segment-actions"].([]interface{})
+
+	for i, v := range actions {
+		action, ok := v.(map[string]interface{})
+
+		if !ok {
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: must be an object, got %T", i, v))
+			continue
+		}
+
+		if action["action"] == "share" {
+			continue
+		}
+
+		if segment, _ := action["segment"].(string); segment == "" {
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: %v action must specify a segment", i, action["action"]))
+		} else if !segments[segment] {
+			errs = append(errs, fmt.Errorf("segment-actions[%d]: %v action segment %q is not defined", i, action["action"], segment))
+		}
+	}
+
+	policies, _ := doc["attachment-policies"].([]interface{})
+	ruleNumbers := make(map[float64]int)
+
+	for i, v := range policies {
+		policy, ok := v.(map[string]interface{})
+
+		if !ok {
+			errs = append(errs, fmt.Errorf("attachment-policies[%d]: must be an object, got %T", i, v))
+			continue
+		}
+
+		ruleNumber, ok := policy["rule-number"].(float64)
+
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("attachment-policies[%d]: rule-number must be a number", i))
+		case ruleNumber != math.Trunc(ruleNumber) || ruleNumber < coreNetworkPolicyRuleNumberMin || ruleNumber > coreNetworkPolicyRuleNumberMax:
+			errs = append(errs, fmt.Errorf("attachment-policies[%d]: rule-number %v must be an integer between %d and %d", i, ruleNumber, coreNetworkPolicyRuleNumberMin, coreNetworkPolicyRuleNumberMax))
+		default:
+			if j, ok := ruleNumbers[ruleNumber]; ok {
+				errs = append(errs, fmt.Errorf("attachment-policies[%d]: rule-number %v is also used by attachment-policies[%d]", i, ruleNumber, j))
+			} else {
+				ruleNumbers[ruleNumber] = i
+			}
+		}
+	}
+
+	return errs
+}
+
+// validCoreNetworkPolicyVersion returns a warning for well-formed versions newer than those known to the provider
+// and an error for malformed or unknown older versions.
+func validCoreNetworkPolicyVersion(v interface{}) (string, error) {
+	version, ok := v.(string)
+
+	if !ok {
+		return "", fmt.Errorf("policy version must be a string, got %T", v)
+	}
+
+	for _, supported := range coreNetworkPolicyVersions {
+		if version == supported {
+			return "", nil
+		}
+	}
+
+	if !coreNetworkPolicyVersionRegexp.MatchString(version) {
+		return "", fmt.Errorf("policy version %q is not valid, expected a version such as %q", version, coreNetworkPolicyVersions[len(coreNetworkPolicyVersions)-1])
+	}
+
+	// Versions are of the form YYYY.MM so they sort lexically.
+	if latest := coreNetworkPolicyVersions[len(coreNetworkPolicyVersions)-1]; version > latest {
+		return fmt.Sprintf("policy version %q is newer than the versions known to the provider (%q) and has not been validated", version, latest), nil
+	}
+
+	return "", fmt.Errorf("policy version %q is not supported, supported versions are %q", version, coreNetworkPolicyVersions)
+}
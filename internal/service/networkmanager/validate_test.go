@@ -0,0 +1,343 @@
+package networkmanager
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestValidateCoreNetworkPolicyDocumentVersion(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName      string
+		Document      string
+		ExpectWarning bool
+		ExpectError   bool
+	}{
+		{
+			TestName: "supported",
+			Document: `{"version":"2021.12"}`,
+		},
+		{
+			TestName: "no version",
+			Document: `{}`,
+		},
+		{
+			TestName:      "newer",
+			Document:      `{"version":"2030.01"}`,
+			ExpectWarning: true,
+		},
+		{
+			TestName:    "older",
+			Document:    `{"version":"2020.01"}`,
+			ExpectError: true,
+		},
+		{
+			TestName:    "typo",
+			Document:    `{"version":"2021.1"}`,
+			ExpectError: true,
+		},
+		{
+			TestName:    "invalid month",
+			Document:    `{"version":"2021.13"}`,
+			ExpectError: true,
+		},
+		{
+			TestName:    "not a string",
+			Document:    `{"version":2021.12}`,
+			ExpectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			ws, es := ValidateCoreNetworkPolicyDocument(testCase.Document, "policy_document")
+
+			if got, want := len(ws) > 0, testCase.ExpectWarning; got != want {
+				t.Errorf("warnings: got %v, expected warning %t", ws, want)
+			}
+
+			if got, want := len(es) > 0, testCase.ExpectError; got != want {
+				t.Errorf("errors: got %v, expected error %t", es, want)
+			}
+		})
+	}
+}
+
+func TestValidateCoreNetworkPolicyDocumentShareActions(t *testing.T) {
+	t.Parallel()
+
+	const segments = `"segments":[{"name":"one"},{"name":"two"},{"name":"three"}]`
+
+	testCases := []struct {
+		TestName       string
+		SegmentActions string
+		ExpectedErrors int
+	}{
+		{
+			TestName:       "share with wildcard",
+			SegmentActions: `[{"action":"share","mode":"attachment-route","segment":"one","share-with":"*"}]`,
+		},
+		{
+			TestName:       "share with list",
+			SegmentActions: `[{"action":"share","segment":"one","share-with":["two","three"]}]`,
+		},
+		{
+			TestName:       "share with except",
+			SegmentActions: `[{"action":"share","segment":"one","share-with":{"except":["two"]}}]`,
+		},
+		{
+			TestName:       "other actions ignored",
+			SegmentActions: `[{"action":"create-route","segment":"undefined","destination-cidr-blocks":["0.0.0.0/0"],"destinations":["blackhole"]}]`,
+		},
+		{
+			TestName:       "undefined source segment",
+			SegmentActions: `[{"action":"share","segment":"undefined","share-with":"*"}]`,
+			ExpectedErrors: 1,
+		},
+		{
+			TestName:       "missing source segment",
+			SegmentActions: `[{"action":"share","share-with":"*"}]`,
+			ExpectedErrors: 1,
+		},
+		{
+			TestName:       "undefined share with segments",
+			SegmentActions: `[{"action":"share","segment":"one","share-with":["two","four","five"]}]`,
+			ExpectedErrors: 2,
+		},
+		{
+			TestName:       "undefined except segment",
+			SegmentActions: `[{"action":"share","segment":"one","share-with":{"except":["four"]}}]`,
+			ExpectedErrors: 1,
+		},
+		{
+			TestName:       "empty except",
+			SegmentActions: `[{"action":"share","segment":"one","share-with":{"except":[]}}]`,
+			ExpectedErrors: 1,
+		},
+		{
+			TestName:       "unexpected share with object key",
+			SegmentActions: `[{"action":"share","segment":"one","share-with":{"except":["two"],"only":["three"]}}]`,
+			ExpectedErrors: 1,
+		},
+		{
+			TestName:       "invalid share with string",
+			SegmentActions: `[{"action":"share","segment":"one","share-with":"two"}]`,
+			ExpectedErrors: 1,
+		},
+		{
+			TestName:       "missing share with",
+			SegmentActions: `[{"action":"share","segment":"one"}]`,
+			ExpectedErrors: 1,
+		},
+		{
+			TestName:       "invalid mode",
+			SegmentActions: `[{"action":"share","mode":"route","segment":"one","share-with":"*"}]`,
+			ExpectedErrors: 1,
+		},
+		{
+			TestName:       "each invalid action reported",
+			SegmentActions: `[{"action":"share","segment":"four","share-with":"*"},{"action":"share","segment":"one","share-with":["two"]},{"action":"share","segment":"one","share-with":["five"]}]`,
+			ExpectedErrors: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			document := fmt.Sprintf(`{"version":"2021.12",%s,"segment-actions":%s}`, segments, testCase.SegmentActions)
+
+			_, es := ValidateCoreNetworkPolicyDocument(document, "policy_document")
+
+			if got, want := len(es), testCase.ExpectedErrors; got != want {
+				t.Errorf("errors: got %v, expected %d errors", es, want)
+			}
+		})
+	}
+}
+
+func TestValidCoreNetworkPolicyRequiredSections(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName      string
+		Document      string
+		ExpectedError string
+	}{
+		{
+			TestName: "valid",
+			Document: `{"version":"2021.12","core-network-configuration":{"asn-ranges":["64512-65534"]},"segments":[{"name":"one"}]}`,
+		},
+		{
+			TestName:      "empty",
+			Document:      `{}`,
+			ExpectedError: "policy document is missing required sections: version, core-network-configuration, segments",
+		},
+		{
+			TestName:      "missing segments",
+			Document:      `{"version":"2021.12","core-network-configuration":{"asn-ranges":["64512-65534"]}}`,
+			ExpectedError: "policy document is missing required sections: segments",
+		},
+		{
+			TestName:      "null core network configuration",
+			Document:      `{"version":"2021.12","core-network-configuration":null,"segments":[{"name":"one"}]}`,
+			ExpectedError: "policy document is missing required sections: core-network-configuration",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			err := validCoreNetworkPolicyRequiredSections(testCase.Document)
+
+			if err == nil {
+				if testCase.ExpectedError != "" {
+					t.Fatalf("expected error %q", testCase.ExpectedError)
+				}
+
+				return
+			}
+
+			if got, want := err.Error(), testCase.ExpectedError; got != want {
+				t.Errorf("got error %q, expected %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidCoreNetworkPolicy(t *testing.T) {
+	t.Parallel()
+
+	const base = `"core-network-configuration":{"asn-ranges":["64512-65534"]},"segments":[{"name":"one"},{"name":"two"}]`
+
+	testCases := []struct {
+		TestName       string
+		Document       string
+		Strict         bool
+		ExpectWarnings int
+		ExpectErrors   int
+	}{
+		{
+			TestName: "valid",
+			Document: `{"version":"2021.12",` + base + `}`,
+		},
+		{
+			TestName:     "missing sections",
+			Document:     `{"version":"2021.12"}`,
+			ExpectErrors: 1,
+		},
+		{
+			TestName:       "newer version",
+			Document:       `{"version":"2030.01",` + base + `}`,
+			ExpectWarnings: 1,
+		},
+		{
+			TestName:     "unsupported version",
+			Document:     `{"version":"2020.01",` + base + `}`,
+			ExpectErrors: 1,
+		},
+		{
+			TestName:     "invalid share action",
+			Document:     `{"version":"2021.12",` + base + `,"segment-actions":[{"action":"share","segment":"one","share-with":["three"]}]}`,
+			ExpectErrors: 1,
+		},
+		{
+			TestName: "strict check not enabled",
+			Document: `{"version":"2021.12",` + base + `,"segment-actions":[{"action":"create-route","segment":"three"}]}`,
+		},
+		{
+			TestName:     "strict check enabled",
+			Document:     `{"version":"2021.12",` + base + `,"segment-actions":[{"action":"create-route","segment":"three"}]}`,
+			Strict:       true,
+			ExpectErrors: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			warnings, errs := validCoreNetworkPolicy(testCase.Document, testCase.Strict)
+
+			if got, want := len(warnings), testCase.ExpectWarnings; got != want {
+				t.Errorf("got %d warnings (%v), expected %d", got, warnings, want)
+			}
+
+			if got, want := len(errs), testCase.ExpectErrors; got != want {
+				t.Errorf("got %d errors (%v), expected %d", got, errs, want)
+			}
+		})
+	}
+}
+
+func TestValidCoreNetworkPolicyStrict(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		TestName       string
+		Document       string
+		ExpectedErrors []string
+	}{
+		{
+			TestName: "valid",
+			Document: `{"version":"2021.12","segments":[{"name":"one"}],"segment-actions":[{"action":"create-route","segment":"one","destination-cidr-blocks":["10.0.0.0/16"]}],"attachment-policies":[{"rule-number":100},{"rule-number":200}]}`,
+		},
+		{
+			TestName: "no actions or policies",
+			Document: `{"version":"2021.12","segments":[{"name":"one"}]}`,
+		},
+		{
+			TestName:       "undefined segment",
+			Document:       `{"segments":[{"name":"one"}],"segment-actions":[{"action":"share","segment":"two"},{"action":"create-route","segment":"two"}]}`,
+			ExpectedErrors: []string{`segment-actions[1]: create-route action segment "two" is not defined`},
+		},
+		{
+			TestName:       "missing segment",
+			Document:       `{"segments":[{"name":"one"}],"segment-actions":[{"action":"create-route"}]}`,
+			ExpectedErrors: []string{"segment-actions[0]: create-route action must specify a segment"},
+		},
+		{
+			TestName: "invalid rule numbers",
+			Document: `{"segments":[{"name":"one"}],"attachment-policies":[{"rule-number":100},{},{"rule-number":"100"},{"rule-number":0},{"rule-number":1.5},{"rule-number":65536},{"rule-number":100}]}`,
+			ExpectedErrors: []string{
+				"attachment-policies[1]: rule-number must be a number",
+				"attachment-policies[2]: rule-number must be a number",
+				"attachment-policies[3]: rule-number 0 must be an integer between 1 and 65535",
+				"attachment-policies[4]: rule-number 1.5 must be an integer between 1 and 65535",
+				"attachment-policies[5]: rule-number 65536 must be an integer between 1 and 65535",
+				"attachment-policies[6]: rule-number 100 is also used by attachment-policies[0]",
+			},
+		},
+		{
+			TestName:       "invalid JSON",
+			Document:       `{`,
+			ExpectedErrors: []string{"policy document is not a JSON object: unexpected end of JSON input"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.TestName, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+
+			for _, err := range validCoreNetworkPolicyStrict(testCase.Document) {
+				got = append(got, err.Error())
+			}
+
+			if !reflect.DeepEqual(got, testCase.ExpectedErrors) {
+				t.Errorf("got errors %q, expected %q", got, testCase.ExpectedErrors)
+			}
+		})
+	}
+}